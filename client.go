@@ -5,212 +5,265 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
-	"net/http/httputil"
-	"strconv"
+	"net/url"
 	"time"
 )
 
 const (
-	endpoint  string = "https://external-api.wallet.halogen.my"
-	version   string = "0.0.8"
-	userAgent string = "wallet/" + version + " lang/go"
+	endpoint        string = "https://external-api.wallet.halogen.my"
+	sandboxEndpoint string = "https://external-api.sandbox.wallet.halogen.my"
+	version         string = "0.0.8"
+	userAgent       string = "wallet/" + version + " lang/go"
 )
 
-type queryInput struct {
-	Name    string      `json:"name"`
-	Payload interface{} `json:"payload"`
-}
+// Environment names a Halogen Wallet deployment.
+type Environment string
 
-func (c *Client) query(ctx context.Context, name string, input interface{}, output interface{}) error {
-	// retriedCount increments on >= 500 errors
-	retriedCount := 0
-retry:
-	body := queryInput{
-		Name:    name,
-		Payload: input,
-	}
-	var jsonBuffer bytes.Buffer
-	if err := json.NewEncoder(&jsonBuffer).Encode(body); err != nil {
-		return err
-	}
-	reqBody := bytes.TrimRight(jsonBuffer.Bytes(), "\n")
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/query", bytes.NewReader(reqBody))
-	if err != nil {
-		return err
-	}
-	jsonBuffer.Reset()
-	req.Header.Set("User-Agent", userAgent)
+const (
+	// EnvironmentProduction targets the live Halogen Wallet API.
+	EnvironmentProduction Environment = "production"
+	// EnvironmentSandbox targets the Halogen Wallet sandbox API.
+	EnvironmentSandbox Environment = "sandbox"
+	// EnvironmentCustom targets the server set in [Options.BaseURL].
+	EnvironmentCustom Environment = "custom"
+)
 
-	o := c.options
-	keyID := ""
-	privateKeyPEM := []byte{}
-	if o.CredentialsLoaderFunc == nil {
-		keyID, privateKeyPEM, err = c.defaultCredentialsLoaderFunc()
-		if err != nil {
-			return err
+// resolveBaseURL returns the server URL for the given environment and base URL.
+func resolveBaseURL(env Environment, baseURL string) (*url.URL, error) {
+	if env == "" {
+		env = EnvironmentProduction
+		if baseURL != "" {
+			env = EnvironmentCustom
 		}
-	} else {
-		keyID, privateKeyPEM, err = o.CredentialsLoaderFunc()
-		if err != nil {
-			return err
+	}
+	switch env {
+	case EnvironmentProduction, EnvironmentSandbox:
+		if baseURL != "" {
+			return nil, fmt.Errorf("wallet: New: BaseURL must be empty for %q environment, use %q environment instead.", env, EnvironmentCustom)
+		}
+		if env == EnvironmentSandbox {
+			baseURL = sandboxEndpoint
+		} else {
+			baseURL = endpoint
 		}
+	case EnvironmentCustom:
+		if baseURL == "" {
+			return nil, fmt.Errorf("wallet: New: BaseURL is required for %q environment.", env)
+		}
+	default:
+		return nil, fmt.Errorf("wallet: New: unknown environment %q. Valid environment would either be %q, %q or %q.", env, EnvironmentProduction, EnvironmentSandbox, EnvironmentCustom)
 	}
-	// clean up the memory when CredentialsLoaderFunc is set.
-	shouldCleanMemory := o.CredentialsLoaderFunc != nil
-	token, err := newToken(keyID, "/query", reqBody, 10*time.Second, shouldCleanMemory)
+	u, err := url.Parse(baseURL)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("wallet: New: invalid BaseURL. err=%v", err)
 	}
-	signature, err := token.signAndFormat(privateKeyPEM)
-	if err != nil {
-		return err
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return nil, fmt.Errorf("wallet: New: BaseURL scheme must be either https or http, got %q.", u.Scheme)
 	}
-	req.Header.Set("Authorization", "Bearer "+signature)
-	if o.Debug {
-		reqB, err := httputil.DumpRequestOut(req, true)
-		if err != nil {
-			return err
-		}
-		log.Printf("INFO: sending request\n%s\n", string(reqB))
+	if u.Host == "" {
+		return nil, fmt.Errorf("wallet: New: BaseURL must include a host.")
 	}
-	resp, err := o.HTTPClient.Do(req)
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("wallet: New: BaseURL must not include user info, query or fragment.")
+	}
+	return u, nil
+}
+
+type requestBody struct {
+	Name           string      `json:"name"`
+	Payload        interface{} `json:"payload"`
+	IdempotencyKey string      `json:"idempotencyKey,omitempty"`
+}
+
+func (c *Client) query(ctx context.Context, name string, input interface{}, output interface{}) error {
+	return c.do(ctx, &Call{Kind: CallKindQuery, Name: name, Input: input, Output: output})
+}
+
+func (c *Client) command(ctx context.Context, name string, input interface{}, output interface{}) error {
+	key, err := idempotencyKey(ctx, name, input)
 	if err != nil {
 		return err
 	}
-	if o.Debug {
-		r, err := httputil.DumpResponse(resp, true)
-		if err != nil {
-			return err
-		}
-		log.Printf("INFO: received response\n%s\n", r)
+	return c.do(ctx, &Call{Kind: CallKindCommand, Name: name, Input: input, Output: output, IdempotencyKey: key})
+}
+
+// do sends call through the interceptor chain.
+func (c *Client) do(ctx context.Context, call *Call) error {
+	if c.err != nil {
+		return c.err
 	}
-	keyID = ""
-	req = nil
-	if resp.StatusCode >= 400 {
-		sdkErr := Error{
-			StatusCode: resp.StatusCode,
-		}
-		if err := json.NewDecoder(resp.Body).Decode(&sdkErr); err != nil {
-			return sdkErr
-		}
-		// rate-limited
-		if resp.StatusCode == http.StatusTooManyRequests {
-			i, err := strconv.ParseInt(resp.Header.Get("Retry-After"), 10, 64)
-			if err != nil {
-				return sdkErr
-			}
-			time.Sleep(time.Duration(i) * time.Second)
-			goto retry
-		}
-		// retry server error
-		if resp.StatusCode >= http.StatusInternalServerError {
-			if retriedCount >= c.options.MaxReadRetry-1 {
-				return sdkErr
-			}
-			retriedCount++
-			time.Sleep(c.options.RetryInterval)
-			goto retry
-		}
-		return sdkErr
+	if c.readOnly && call.Kind == CallKindCommand {
+		return fmt.Errorf("%w: command %s refused", ErrReadOnly, call.Name)
 	}
-	return json.NewDecoder(resp.Body).Decode(&output)
+	if err := validate(call.Name, call.Input); err != nil {
+		return err
+	}
+	if call.Header == nil {
+		call.Header = http.Header{}
+	}
+	return c.pipeline(ctx, call)
 }
 
-type commandInput struct {
-	Name    string      `json:"name"`
-	Payload interface{} `json:"payload"`
-}
+// roundTrip is the innermost step of the chain. It signs and sends the call once, then
+// decodes the response into call.Output or into an [Error].
+func (c *Client) roundTrip(ctx context.Context, call *Call) error {
+	call.Attempt++
+	call.StatusCode = 0
+	call.ResponseHeader = nil
 
-func (c *Client) command(ctx context.Context, name string, input interface{}, output interface{}) error {
-	// only retry rate limited errors.
-retry:
-	body := commandInput{
-		Name:    name,
-		Payload: input,
+	body := requestBody{
+		Name:           call.Name,
+		Payload:        call.Input,
+		IdempotencyKey: call.IdempotencyKey,
 	}
 	var jsonBuffer bytes.Buffer
 	if err := json.NewEncoder(&jsonBuffer).Encode(body); err != nil {
 		return err
 	}
+	uri := "/" + string(call.Kind)
 	reqBody := bytes.TrimRight(jsonBuffer.Bytes(), "\n")
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/command", bytes.NewReader(reqBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+uri, bytes.NewReader(reqBody))
 	if err != nil {
 		return err
 	}
-	jsonBuffer.Reset()
+	for k, v := range call.Header {
+		req.Header[k] = v
+	}
 	req.Header.Set("User-Agent", userAgent)
 
 	o := c.options
-	keyID := ""
-	privateKeyPEM := []byte{}
-	if o.CredentialsLoaderFunc == nil {
-		keyID, privateKeyPEM, err = c.defaultCredentialsLoaderFunc()
+	signStart := time.Now()
+	signer, err := c.signer()
+	if err != nil {
+		return err
+	}
+	var keyID, alg string
+	ps, isPEM := signer.(*pemSigner)
+	if isPEM {
+		// the private key is only parsed once the rate limiter lets the call through, and is
+		// cleared whatever happens next.
+		defer ps.discard()
+		keyID = ps.keyID
+	} else {
+		keyID, alg, err = signer.SigningKey(ctx)
+	}
+	call.SigningDuration += time.Since(signStart)
+	if err != nil {
+		return err
+	}
+	call.KeyID = keyID
+	dryRun := c.dryRun && call.Kind == CallKindCommand
+	if o.RateLimiter != nil && !dryRun {
+		wait, err := o.RateLimiter.Wait(ctx, keyID)
+		call.RateLimitWait += wait
 		if err != nil {
 			return err
 		}
-	} else {
-		keyID, privateKeyPEM, err = o.CredentialsLoaderFunc()
+	}
+	if isPEM {
+		signStart = time.Now()
+		_, alg, err = ps.SigningKey(ctx)
+		call.SigningDuration += time.Since(signStart)
 		if err != nil {
 			return err
 		}
 	}
-	// clean up the memory when CredentialsLoaderFunc is set.
-	shouldCleanMemory := o.CredentialsLoaderFunc != nil
-	token, err := newToken(keyID, "/command", reqBody, 10*time.Second, shouldCleanMemory)
+	token, err := newToken(keyID, c.basePath+uri, reqBody, 10*time.Second, false)
 	if err != nil {
 		return err
 	}
-	signature, err := token.signAndFormat(privateKeyPEM)
+	jsonBuffer.Reset()
+	signStart = time.Now()
+	signature, err := token.signWith(ctx, signer, keyID, alg)
+	call.SigningDuration += time.Since(signStart)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Authorization", "Bearer "+signature)
-	if o.Debug {
-		reqB, err := httputil.DumpRequestOut(req, true)
-		if err != nil {
-			return err
-		}
-		log.Printf("INFO: sending request\n%s\n", string(reqB))
+	if dryRun {
+		return c.fakeRoundTrip(ctx, call, token, reqBody)
 	}
+	req.Header.Set("Authorization", "Bearer "+signature)
+	c.logBody(ctx, "wallet: sending request", call, req.Header, reqBody, false)
 	resp, err := o.HTTPClient.Do(req)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %s: %w", ErrTransport, call.Name, err)
 	}
-	if o.Debug {
-		r, err := httputil.DumpResponse(resp, true)
+	defer resp.Body.Close()
+	if c.logsBodies(ctx) {
+		respBody, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return err
+			return fmt.Errorf("%w: %s: %w", ErrTransport, call.Name, err)
 		}
-		log.Printf("INFO: received response\n%s\n", r)
+		c.logBody(ctx, "wallet: received response", call, resp.Header, respBody, true)
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
 	}
 	keyID = ""
 	req = nil
+	call.StatusCode = resp.StatusCode
+	call.ResponseHeader = resp.Header
+	if resp.StatusCode == http.StatusTooManyRequests {
+		call.RateLimitedAttempts++
+	}
+	retryAfter, hasRetryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+	if resp.StatusCode == http.StatusTooManyRequests && hasRetryAfter && o.RateLimiter != nil {
+		o.RateLimiter.Pause(call.KeyID, retryAfter)
+	}
 	if resp.StatusCode >= 400 {
 		sdkErr := Error{
 			StatusCode: resp.StatusCode,
+			RetryAfter: retryAfter,
+			APIName:    call.Name,
 		}
-		if err := json.NewDecoder(resp.Body).Decode(&sdkErr); err != nil {
-			return sdkErr
-		}
-		// rate-limited
-		if resp.StatusCode == http.StatusTooManyRequests {
-			i, err := strconv.ParseInt(resp.Header.Get("Retry-After"), 10, 64)
-			if err != nil {
-				return sdkErr
-			}
-			time.Sleep(time.Duration(i) * time.Second)
-			goto retry
+		// the body is decoded at best-effort, sdkErr carries the status code regardless.
+		_ = json.NewDecoder(resp.Body).Decode(&sdkErr)
+		if sdkErr.RequestID == "" {
+			sdkErr.RequestID = resp.Header.Get("X-Request-Id")
 		}
 		return sdkErr
 	}
-	return json.NewDecoder(resp.Body).Decode(&output)
+	if err := json.NewDecoder(resp.Body).Decode(call.Output); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrDecode, call.Name, err)
+	}
+	return nil
+}
+
+// signer returns the Signer for the next request: [Options.Signer] when set, then the cached
+// key set with SetCachedCredentials, otherwise a single-use signer over the PEM credentials.
+func (c *Client) signer() (Signer, error) {
+	o := c.options
+	if o.Signer != nil {
+		return o.Signer, nil
+	}
+	if creds := c.credentials.Load(); o.CredentialsLoaderFunc == nil && creds != nil && creds.signer != nil {
+		return creds.signer, nil
+	}
+	var keyID string
+	var privateKeyPEM []byte
+	var err error
+	if o.CredentialsLoaderFunc == nil {
+		keyID, privateKeyPEM, err = c.defaultCredentialsLoaderFunc()
+	} else {
+		keyID, privateKeyPEM, err = o.CredentialsLoaderFunc()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pemSigner{
+		keyID:          keyID,
+		privateKeyPEM:  privateKeyPEM,
+		passphraseFunc: o.PassphraseFunc,
+		algorithm:      o.Algorithm,
+		// clean up the memory when CredentialsLoaderFunc is set.
+		shouldCleanKey: o.CredentialsLoaderFunc != nil,
+	}, nil
 }
 
 func (c *Client) defaultCredentialsLoaderFunc() (keyID string, privateKeyPEM []byte, err error) {
-	if c.credentials == nil {
+	creds := c.credentials.Load()
+	if creds == nil {
 		return "", nil, fmt.Errorf("credentials are not set. You may either use SetCredentials or provide CredentialsLoaderFunc upon client initialization.")
 	}
-	return c.credentials.keyID, c.credentials.privateKeyPEM, nil
+	return creds.keyID, creds.privateKeyPEM, nil
 }
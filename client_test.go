@@ -0,0 +1,110 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestECKeyPEM(t testing.TB) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func decodeTestTokenPayload(t *testing.T, r *http.Request) tokenPayload {
+	t.Helper()
+	jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("malformed token %q", jwt)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload tokenPayload
+	if err := json.Unmarshal(b, &payload); err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func TestClientWithBaseURL(t *testing.T) {
+	var paths, uris []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		uris = append(uris, decodeTestTokenPayload(t, r).Uri)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := New(&Options{BaseURL: srv.URL + "/wallet/"})
+	c.SetCredentials(testKeyID, newTestECKeyPEM(t))
+	if _, err := c.ListClientAccounts(context.Background(), &ListClientAccountsInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UpdateDisplayCurrency(context.Background(), &UpdateDisplayCurrencyInput{DisplayCurrency: "MYR"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/wallet/query", "/wallet/command"}
+	for i := range want {
+		if paths[i] != want[i] || uris[i] != want[i] {
+			t.Errorf("call %d: got path=%q uri=%q, want %q", i, paths[i], uris[i], want[i])
+		}
+	}
+}
+
+func TestClientWithInvalidEnvironment(t *testing.T) {
+	tests := []Options{
+		{Environment: EnvironmentCustom},
+		{Environment: EnvironmentProduction, BaseURL: "https://example.com"},
+		{Environment: "staging"},
+		{BaseURL: "ftp://example.com"},
+		{BaseURL: "https://example.com?a=b"},
+	}
+	for _, o := range tests {
+		c := New(&o)
+		if _, err := c.ListClientAccounts(context.Background(), &ListClientAccountsInput{}); err == nil {
+			t.Errorf("expected configuration error for environment=%q baseURL=%q", o.Environment, o.BaseURL)
+		}
+	}
+}
+
+func TestClientWithOptionsCopy(t *testing.T) {
+	httpClient := &http.Client{}
+	o := &Options{
+		HTTPClient:   httpClient,
+		Interceptors: []Interceptor{func(ctx context.Context, call *Call, next Next) error { return next(ctx, call) }},
+		RetryPolicy:  &RetryPolicy{MaxServerErrorAttempts: 2},
+	}
+	c := New(o)
+	if o.MaxReadRetry != 0 || o.RetryInterval != 0 || httpClient.Timeout != 0 {
+		t.Fatalf("expected the options not to be modified, got %+v and timeout %s", o, httpClient.Timeout)
+	}
+	if c.options == o || c.options.HTTPClient == httpClient || c.options.RetryPolicy == o.RetryPolicy || c.options.HTTPClient.Timeout <= 0 {
+		t.Fatal("expected the client to keep a copy of the options with defaults")
+	}
+
+	o.Interceptors[0] = nil
+	o.RetryPolicy.MaxServerErrorAttempts = 10
+	if c.options.Interceptors[0] == nil || c.options.RetryPolicy.MaxServerErrorAttempts != 2 {
+		t.Fatal("expected changes to the options after New to be ignored")
+	}
+}
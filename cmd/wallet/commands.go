@@ -0,0 +1,624 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	wallet "github.com/halogencapital/wallet-go"
+)
+
+// caller sends the input bound by a command's flags and returns the output.
+type caller func(ctx context.Context, c *wallet.Client) (any, error)
+
+type command struct {
+	// name is the subcommand, for instance "accounts list".
+	name    string
+	summary string
+	// api is the name of the query or command sent to the server.
+	api string
+	// command reports whether the subcommand sends a command, which asks for confirmation.
+	command bool
+	// local reports whether the subcommand runs without calling the API, in which case its
+	// caller is given a nil client.
+	local bool
+	// required lists the flags that must be set.
+	required []string
+	// setup binds the flags of the subcommand to its input, and returns the input and the call.
+	setup func(fs *flag.FlagSet) (any, caller)
+}
+
+// preflight returns the error of a pre-flight check, or the violations of its report.
+func preflight(report *wallet.ValidationReport, err error) error {
+	if err != nil {
+		return err
+	}
+	return report.Err()
+}
+
+// generatedKey is the output of "keys generate".
+type generatedKey struct {
+	KeyType        string `json:"keyType"`
+	PrivateKeyFile string `json:"privateKeyFile"`
+	CSRFile        string `json:"csrFile"`
+	Encrypted      bool   `json:"encrypted"`
+}
+
+// savedFile is the output of the subcommands downloading a document.
+type savedFile struct {
+	Path  string `json:"path"`
+	Bytes int    `json:"bytes"`
+}
+
+// save writes a downloaded document to path, defaulted to the base name of the filename sent
+// by the server in the working directory. It does not overwrite an existing file.
+func save(path string, filename string, b []byte) (*savedFile, error) {
+	if path == "" {
+		path = filepath.Base(filename)
+		if filename == "" || path == "." || path == ".." || path == string(filepath.Separator) {
+			return nil, fmt.Errorf("invalid document filename %q, set --out", filename)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return &savedFile{Path: path, Bytes: len(b)}, nil
+}
+
+var commands = []command{
+	{
+		name:    "accounts list",
+		summary: "List the client's accounts",
+		api:     "list_client_accounts",
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.ListClientAccountsInput{}
+			fs.Var(stringsFlag[string]{&input.AccountIDs}, "account", "account ID to list, repeatable")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.ListClientAccounts(ctx, input)
+			}
+		},
+	},
+	{
+		name:    "accounts performance",
+		summary: "Show the performance of accounts over a timeframe",
+		api:     "list_client_account_performance",
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.ListClientAccountPerformanceInput{}
+			fs.Var(stringsFlag[string]{&input.AccountIDs}, "account", "account ID, repeatable")
+			fs.TextVar(&input.Timeframe, "timeframe", wallet.Timeframe(""), "timeframe, one of 1m, 3m, 6m, ytd, 1y, 3y or all")
+			fs.TextVar(&input.Interval, "interval", wallet.Interval(""), "interval between points, one of daily, weekly or monthly")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.ListClientAccountPerformance(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "accounts rename",
+		summary:  "Rename an account",
+		api:      "update_account_name",
+		command:  true,
+		required: []string{"account", "name"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.UpdateAccountNameInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.StringVar(&input.AccountName, "name", "", "new account name")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.UpdateAccountName(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "balance",
+		summary:  "Show the holdings of an account",
+		api:      "list_client_account_balance",
+		required: []string{"account"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.ListClientAccountBalanceInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.ListClientAccountBalance(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "allocation performance",
+		summary:  "Show the performance of a fund allocation within an account",
+		api:      "get_client_account_allocation_performance",
+		required: []string{"account", "allocation"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.GetClientAccountAllocationPerformanceInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.StringVar(&input.AllocationID, "allocation", "", "allocation ID")
+			fs.StringVar(&input.Type, "type", "", "allocation type, for instance fund")
+			fs.IntVar(&input.FundClassSequence, "class", 0, "fund class sequence")
+			fs.TextVar(&input.Timeframe, "timeframe", wallet.Timeframe(""), "timeframe, one of 1m, 3m, 6m, ytd, 1y, 3y or all")
+			fs.TextVar(&input.Interval, "interval", wallet.Interval(""), "interval between points, one of daily, weekly or monthly")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.GetClientAccountAllocationPerformance(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "statement download",
+		summary:  "Download the statement of an account",
+		api:      "get_client_account_statement",
+		required: []string{"account", "from", "to"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.GetClientAccountStatementInput{}
+			var path string
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.TextVar(&input.FromDate, "from", wallet.Date{}, "first day, in YYYY-MM-DD format")
+			fs.TextVar(&input.ToDate, "to", wallet.Date{}, "last day, in YYYY-MM-DD format")
+			fs.TextVar(&input.Format, "format", wallet.DocumentFormatPDF, "document format, either pdf or html")
+			fs.StringVar(&path, "out", "", "file to write, defaulted to the statement's filename, never overwritten")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				output, err := c.GetClientAccountStatement(ctx, input)
+				if err != nil {
+					return nil, err
+				}
+				return save(path, output.Filename, output.Bytes)
+			}
+		},
+	},
+	{
+		name:     "requests list",
+		summary:  "List the requests of an account",
+		api:      "list_client_account_requests",
+		required: []string{"account"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.ListClientAccountRequestsInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.Var(optionalStringFlag{&input.RequestID}, "request", "request ID")
+			fs.Var(stringPointersFlag{&input.FundIDs}, "fund", "fund ID, repeatable")
+			fs.Var(optionalDateFlag{&input.FromDate}, "from", "first day, in YYYY-MM-DD format")
+			fs.Var(optionalDateFlag{&input.ToDate}, "to", "last day, in YYYY-MM-DD format")
+			fs.Var(stringsFlag[wallet.RequestType]{&input.Types}, "type", "request type, repeatable: investment, redemption, switch out, switch in, deposit or withdrawal")
+			fs.Var(stringsFlag[wallet.RequestStatus]{&input.Statuses}, "status", "request status, repeatable: pending, completed, cancelled or rejected")
+			fs.Var(optionalIntFlag{&input.Limit}, "limit", "maximum number of requests, or page size with --all")
+			fs.Var(optionalIntFlag{&input.Offset}, "offset", "number of requests to skip")
+			fs.BoolVar(&input.CompletedOnly, "completed", false, "list completed requests only")
+			var all bool
+			fs.BoolVar(&all, "all", false, "list all the requests, fetching them page by page")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				if !all {
+					return c.ListClientAccountRequests(ctx, input)
+				}
+				requests, err := c.CollectClientAccountRequests(ctx, input)
+				if err != nil {
+					return nil, err
+				}
+				return &wallet.ListClientAccountRequestsOutput{Requests: requests}, nil
+			}
+		},
+	},
+	{
+		name:     "requests confirmation",
+		summary:  "Download the confirmation of a request",
+		api:      "get_client_account_request_confirmation",
+		required: []string{"account", "request"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.GetClientAccountRequestConfirmationInput{}
+			var path string
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.StringVar(&input.RequestID, "request", "", "request ID")
+			fs.TextVar(&input.Format, "format", wallet.DocumentFormatPDF, "document format, either pdf or html")
+			fs.StringVar(&path, "out", "", "file to write, defaulted to the confirmation's filename, never overwritten")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				output, err := c.GetClientAccountRequestConfirmation(ctx, input)
+				if err != nil {
+					return nil, err
+				}
+				return save(path, output.Filename, output.Bytes)
+			}
+		},
+	},
+	{
+		name:     "requests policy",
+		summary:  "Show the approval policy of a request",
+		api:      "get_client_account_request_policy",
+		required: []string{"account", "request"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.GetClientAccountRequestPolicyInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.StringVar(&input.RequestID, "request", "", "request ID")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.GetClientAccountRequestPolicy(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "requests cancel",
+		summary:  "Cancel a pending request",
+		api:      "create_request_cancellation",
+		command:  true,
+		required: []string{"account", "request"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.CreateRequestCancellationInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.StringVar(&input.RequestID, "request", "", "request ID")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.CreateRequestCancellation(ctx, input)
+			}
+		},
+	},
+	{
+		name:    "profile get",
+		summary: "Show the client's profile",
+		api:     "get_client_profile",
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.GetClientProfileInput{}
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.GetClientProfile(ctx, input)
+			}
+		},
+	},
+	{
+		name:    "profile update",
+		summary: "Update the client's profile",
+		api:     "update_client_profile",
+		command: true,
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.UpdateClientProfileInput{}
+			fs.StringVar(&input.Ethnicity, "ethnicity", "", "ethnicity")
+			fs.StringVar(&input.OtherEthnicity, "other-ethnicity", "", "ethnicity, when --ethnicity is other")
+			fs.StringVar(&input.DomesticRinggitBorrowing, "domestic-ringgit-borrowing", "", "domestic ringgit borrowing")
+			fs.StringVar(&input.TaxResidency, "tax-residency", "", "tax residency")
+			fs.StringVar(&input.CountryTax, "country-tax", "", "country of tax residence")
+			fs.StringVar(&input.TaxIdentificationNo, "tax-id", "", "tax identification number")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.UpdateClientProfile(ctx, input)
+			}
+		},
+	},
+	{
+		name:    "referral get",
+		summary: "Show the client's referral code",
+		api:     "get_client_referral",
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.GetClientReferralInput{}
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.GetClientReferral(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "fund get",
+		summary:  "Show a fund",
+		api:      "get_fund",
+		required: []string{"fund"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.GetFundInput{}
+			fs.StringVar(&input.FundID, "fund", "", "fund ID")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.GetFund(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "fund price",
+		summary:  "Show the projected price of a fund class",
+		api:      "get_projected_fund_price",
+		required: []string{"fund", "class"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.GetProjectedFundPriceInput{}
+			fs.StringVar(&input.FundID, "fund", "", "fund ID")
+			fs.IntVar(&input.FundClassSequence, "class", 0, "fund class sequence")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.GetProjectedFundPrice(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "funds list",
+		summary:  "List the funds an account may invest in",
+		api:      "list_funds_for_subscription",
+		required: []string{"account"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.ListFundsForSubscriptionInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.ListFundsForSubscription(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "consents list",
+		summary:  "List the consents required to invest in a fund class",
+		api:      "list_invest_consents",
+		required: []string{"account", "fund", "class"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.ListInvestConsentsInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.StringVar(&input.FundID, "fund", "", "fund ID")
+			fs.IntVar(&input.FundClassSequence, "class", 0, "fund class sequence")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.ListInvestConsents(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "voucher get",
+		summary:  "Show the discount of a voucher on an investment",
+		api:      "get_voucher",
+		required: []string{"account", "fund", "class", "amount", "code"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.GetVoucherInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.StringVar(&input.FundID, "fund", "", "fund ID")
+			fs.IntVar(&input.FundClassSequence, "class", 0, "fund class sequence")
+			fs.TextVar(&input.Amount, "amount", wallet.Decimal{}, "amount to invest")
+			fs.Var(optionalStringFlag{&input.VoucherCode}, "code", "voucher code")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.GetVoucher(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "invest preview",
+		summary:  "Preview the fees of an investment",
+		api:      "get_preview_invest",
+		required: []string{"account", "fund", "class", "amount"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.GetPreviewInvestInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.StringVar(&input.FundID, "fund", "", "fund ID")
+			fs.IntVar(&input.FundClassSequence, "class", 0, "fund class sequence")
+			fs.TextVar(&input.Amount, "amount", wallet.Decimal{}, "amount to invest")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.GetPreviewInvest(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "invest",
+		summary:  "Invest in a fund class",
+		api:      "create_investment_request",
+		command:  true,
+		required: []string{"account", "fund", "class", "amount"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.CreateInvestmentRequestInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.StringVar(&input.FundID, "fund", "", "fund ID")
+			fs.IntVar(&input.FundClassSequence, "class", 0, "fund class sequence")
+			fs.TextVar(&input.Amount, "amount", wallet.Decimal{}, "amount to invest")
+			fs.Var(setFlag{&input.Consents}, "consent", "consent given, repeatable, see \"wallet consents list\"")
+			fs.StringVar(&input.VoucherCode, "voucher", "", "voucher code")
+			var check bool
+			fs.BoolVar(&check, "check", false, "run the pre-flight checks first, and only send the command if they pass")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				if check {
+					if err := preflight(c.ValidateInvestment(ctx, input)); err != nil {
+						return nil, err
+					}
+				}
+				return c.CreateInvestmentRequest(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "redeem",
+		summary:  "Redeem from a fund class, by amount or units",
+		api:      "create_redemption_request",
+		command:  true,
+		required: []string{"account", "fund", "class"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.CreateRedemptionRequestInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.StringVar(&input.FundID, "fund", "", "fund ID")
+			fs.IntVar(&input.FundClassSequence, "class", 0, "fund class sequence")
+			fs.TextVar(&input.RequestedAmount, "amount", wallet.Decimal{}, "amount to redeem")
+			fs.TextVar(&input.Units, "units", wallet.Decimal{}, "units to redeem")
+			fs.StringVar(&input.ToBankAccountNumber, "bank-account", "", "bank account number to pay to")
+			var check bool
+			fs.BoolVar(&check, "check", false, "run the pre-flight checks first, and only send the command if they pass")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				if check {
+					if err := preflight(c.ValidateRedemption(ctx, input)); err != nil {
+						return nil, err
+					}
+				}
+				return c.CreateRedemptionRequest(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "switch",
+		summary:  "Switch from a fund class to another, by amount or units",
+		api:      "create_switch_request",
+		command:  true,
+		required: []string{"account", "from-fund", "from-class", "to-fund", "to-class"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.CreateSwitchRequestInput{}
+			fs.StringVar(&input.AccountID, "account", "", "account ID")
+			fs.StringVar(&input.SwitchFromFundID, "from-fund", "", "fund ID to switch from")
+			fs.IntVar(&input.SwitchFromFundClassSequence, "from-class", 0, "fund class sequence to switch from")
+			fs.StringVar(&input.SwitchToFundID, "to-fund", "", "fund ID to switch to")
+			fs.IntVar(&input.SwitchToFundClassSequence, "to-class", 0, "fund class sequence to switch to")
+			fs.TextVar(&input.RequestedAmount, "amount", wallet.Decimal{}, "amount to switch")
+			fs.TextVar(&input.Units, "units", wallet.Decimal{}, "units to switch")
+			var check bool
+			fs.BoolVar(&check, "check", false, "run the pre-flight checks first, and only send the command if they pass")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				if check {
+					if err := preflight(c.ValidateSwitch(ctx, input)); err != nil {
+						return nil, err
+					}
+				}
+				return c.CreateSwitchRequest(ctx, input)
+			}
+		},
+	},
+	{
+		name:    "bank-accounts list",
+		summary: "List the client's bank accounts",
+		api:     "list_client_bank_accounts",
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.ListClientBankAccountsInput{}
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.ListClientBankAccounts(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "bank-accounts add",
+		summary:  "Add a bank account",
+		api:      "create_client_bank_account",
+		command:  true,
+		required: []string{"number", "name", "bank"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			bankAccount := &wallet.BankAccount{}
+			input := &wallet.CreateClientBankAccountInput{BankAccount: bankAccount}
+			fs.StringVar(&bankAccount.AccountNumber, "number", "", "account number")
+			fs.StringVar(&bankAccount.AccountName, "name", "", "account holder name")
+			fs.StringVar(&bankAccount.AccountCurrency, "currency", "MYR", "account currency")
+			fs.StringVar(&bankAccount.AccountType, "type", "", "account type")
+			fs.StringVar(&bankAccount.BankName, "bank", "", "bank name, see \"wallet banks list\"")
+			fs.StringVar(&bankAccount.BankBic, "bic", "", "bank BIC")
+			fs.StringVar(&bankAccount.ReferenceNumber, "reference", "", "reference number")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.CreateClientBankAccount(ctx, input)
+			}
+		},
+	},
+	{
+		name:    "banks list",
+		summary: "List the supported banks",
+		api:     "list_banks",
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.ListBanksInput{}
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.ListBanks(ctx, input)
+			}
+		},
+	},
+	{
+		name:    "currencies list",
+		summary: "List the display currencies",
+		api:     "list_display_currencies",
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.ListDisplayCurrenciesInput{}
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.ListDisplayCurrencies(ctx, input)
+			}
+		},
+	},
+	{
+		name:     "currencies set",
+		summary:  "Set the display currency",
+		api:      "update_display_currency",
+		command:  true,
+		required: []string{"currency"},
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.UpdateDisplayCurrencyInput{}
+			fs.StringVar(&input.DisplayCurrency, "currency", "", "currency code, see \"wallet currencies list\"")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.UpdateDisplayCurrency(ctx, input)
+			}
+		},
+	},
+	{
+		name:    "suitability list",
+		summary: "List the client's suitability assessments",
+		api:     "list_client_suitability_assessments",
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.ListClientSuitabilityAssessmentsInput{}
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.ListClientSuitabilityAssessments(ctx, input)
+			}
+		},
+	},
+	{
+		name:    "suitability create",
+		summary: "Submit a suitability assessment",
+		api:     "create_suitability_assessment",
+		command: true,
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			assessment := &wallet.SuitabilityAssessment{}
+			input := &wallet.CreateSuitabilityAssessmentInput{SuitabilityAssessment: assessment}
+			fs.StringVar(&assessment.InvestmentExperience, "experience", "", "investment experience")
+			fs.StringVar(&assessment.InvestmentObjective, "objective", "", "investment objective")
+			fs.StringVar(&assessment.InvestmentHorizon, "horizon", "", "investment horizon")
+			fs.StringVar(&assessment.CurrentInvestment, "current-investment", "", "current investment")
+			fs.StringVar(&assessment.ReturnExpectations, "return-expectations", "", "return expectations")
+			fs.StringVar(&assessment.Attachment, "attachment", "", "attachment")
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.CreateSuitabilityAssessment(ctx, input)
+			}
+		},
+	},
+	{
+		name:    "promos list",
+		summary: "List the client's promotions",
+		api:     "list_client_promos",
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.ListClientPromosInput{}
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.ListClientPromos(ctx, input)
+			}
+		},
+	},
+	{
+		name:    "payment-methods list",
+		summary: "List the available payment methods",
+		api:     "list_payment_methods",
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.ListPaymentMethodsInput{}
+			return input, func(ctx context.Context, c *wallet.Client) (any, error) {
+				return c.ListPaymentMethods(ctx, input)
+			}
+		},
+	},
+	{
+		name:    "keys generate",
+		summary: "Generate a private key and the CSR to create an API key with",
+		local:   true,
+		setup: func(fs *flag.FlagSet) (any, caller) {
+			input := &wallet.GenerateKeyPairInput{}
+			var keyFile, csrFile, passphraseFile string
+			fs.StringVar(&input.KeyType, "type", wallet.KeyTypeEC, "key type, either ec for EC P-256 or rsa for RSA-4096")
+			fs.StringVar(&keyFile, "key-out", "", "path of the private key, written with 0600 permissions, defaulted to .key/<type>_private_key.pem")
+			fs.StringVar(&csrFile, "csr-out", "", "path of the CSR, defaulted to .key/<type>_csr.pem")
+			fs.StringVar(&passphraseFile, "passphrase-file", "", "path of a file holding the passphrase to encrypt the private key with")
+			fs.StringVar(&input.Subject.CommonName, "cn", "", "common name of the CSR subject")
+			fs.Var(stringsFlag[string]{&input.Subject.Organization}, "org", "organization of the CSR subject")
+			fs.Var(stringsFlag[string]{&input.Subject.OrganizationalUnit}, "unit", "organizational unit of the CSR subject")
+			fs.Var(stringsFlag[string]{&input.Subject.Locality}, "locality", "locality of the CSR subject")
+			fs.Var(stringsFlag[string]{&input.Subject.Province}, "state", "state of the CSR subject")
+			fs.Var(stringsFlag[string]{&input.Subject.Country}, "country", "two-letter country code of the CSR subject")
+			return input, func(ctx context.Context, _ *wallet.Client) (any, error) {
+				if passphraseFile != "" {
+					b, err := os.ReadFile(passphraseFile)
+					if err != nil {
+						return nil, err
+					}
+					input.Passphrase = bytes.TrimRight(b, "\r\n")
+				}
+				pair, err := wallet.GenerateKeyPair(input)
+				if err != nil {
+					return nil, err
+				}
+				if keyFile == "" {
+					keyFile = filepath.Join(".key", pair.KeyType+"_private_key.pem")
+				}
+				if csrFile == "" {
+					csrFile = filepath.Join(".key", pair.KeyType+"_csr.pem")
+				}
+				if err := pair.WriteFiles(keyFile, csrFile); err != nil {
+					return nil, err
+				}
+				return &generatedKey{KeyType: pair.KeyType, PrivateKeyFile: keyFile, CSRFile: csrFile, Encrypted: passphraseFile != ""}, nil
+			}
+		},
+	},
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	wallet "github.com/halogencapital/wallet-go"
+)
+
+// stringsFlag is a repeatable flag also accepting comma separated values.
+type stringsFlag[T ~string] struct {
+	values *[]T
+}
+
+func (f stringsFlag[T]) String() string {
+	if f.values == nil {
+		return ""
+	}
+	values := make([]string, len(*f.values))
+	for i, v := range *f.values {
+		values[i] = string(v)
+	}
+	return strings.Join(values, ",")
+}
+
+func (f stringsFlag[T]) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*f.values = append(*f.values, T(v))
+		}
+	}
+	return nil
+}
+
+// stringPointersFlag is a stringsFlag for optional list filters.
+type stringPointersFlag struct {
+	values *[]*string
+}
+
+func (f stringPointersFlag) String() string {
+	if f.values == nil {
+		return ""
+	}
+	values := make([]string, len(*f.values))
+	for i, v := range *f.values {
+		values[i] = *v
+	}
+	return strings.Join(values, ",")
+}
+
+func (f stringPointersFlag) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*f.values = append(*f.values, &v)
+		}
+	}
+	return nil
+}
+
+// optionalStringFlag sets a *string only when the flag is given.
+type optionalStringFlag struct {
+	value **string
+}
+
+func (f optionalStringFlag) String() string {
+	if f.value == nil || *f.value == nil {
+		return ""
+	}
+	return **f.value
+}
+
+func (f optionalStringFlag) Set(s string) error {
+	*f.value = &s
+	return nil
+}
+
+// optionalDateFlag sets a *wallet.Date only when the flag is given.
+type optionalDateFlag struct {
+	value **wallet.Date
+}
+
+func (f optionalDateFlag) String() string {
+	if f.value == nil || *f.value == nil {
+		return ""
+	}
+	return (*f.value).String()
+}
+
+func (f optionalDateFlag) Set(s string) error {
+	d, err := wallet.ParseDate(s)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	*f.value = &d
+	return nil
+}
+
+// optionalIntFlag sets a *int only when the flag is given.
+type optionalIntFlag struct {
+	value **int
+}
+
+func (f optionalIntFlag) String() string {
+	if f.value == nil || *f.value == nil {
+		return ""
+	}
+	return strconv.Itoa(**f.value)
+}
+
+func (f optionalIntFlag) Set(s string) error {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", s)
+	}
+	*f.value = &i
+	return nil
+}
+
+// setFlag is a repeatable flag setting keys of a map to true, for instance consents.
+type setFlag struct {
+	values *map[string]bool
+}
+
+func (f setFlag) String() string {
+	if f.values == nil {
+		return ""
+	}
+	keys := make([]string, 0, len(*f.values))
+	for k := range *f.values {
+		keys = append(keys, k)
+	}
+	return strings.Join(keys, ",")
+}
+
+func (f setFlag) Set(s string) error {
+	if *f.values == nil {
+		*f.values = map[string]bool{}
+	}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			(*f.values)[v] = true
+		}
+	}
+	return nil
+}
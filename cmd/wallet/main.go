@@ -0,0 +1,377 @@
+// Command wallet calls the Halogen Wallet API from the command line.
+//
+// Every query and command of [wallet.Client] is exposed as a subcommand:
+//
+//	wallet accounts list
+//	wallet balance --account 9b2f...
+//	wallet invest --account 9b2f... --fund 1c5e... --class 1 --amount 1000 --consent IM
+//	wallet statement download --account 9b2f... --from 2025-01-01 --to 2025-12-31 --format pdf
+//
+// Run "wallet help" for the full list, and "wallet <command> -h" for the flags of a command.
+//
+// # Credentials
+//
+// Credentials are read, in order of precedence, from:
+//
+//   - the --key-id and --key-file flags,
+//   - the WALLET_KEY_ID environment variable, with either WALLET_KEY_FILE holding the path of the
+//     PEM encoded private key or WALLET_PRIVATE_KEY holding the key itself,
+//   - the JSON file given by --credentials or WALLET_CREDENTIALS, holding "keyId" and either
+//     "privateKeyFile" or "privateKey".
+//
+// The private key is loaded for every request and cleared from memory afterwards. An encrypted
+// PKCS #8 private key is decrypted with the passphrase read from the file given by
+// --key-passphrase-file or WALLET_KEY_PASSPHRASE_FILE.
+//
+// # Output
+//
+// Results are printed as a table by default. Use --output json or --output yaml for scripting.
+//
+// # Commands
+//
+// Subcommands sending a command, such as invest or redeem, print the payload and ask for
+// confirmation before sending it. Use --yes to skip the confirmation, and --dry-run to validate
+// and sign the command without sending it: its redacted payload and token claims are logged to
+// stderr, along with the exact payload with --debug, and the synthetic output of
+// [wallet.Options.DryRun] is printed. Set --read-only or WALLET_READ_ONLY=true to refuse every
+// command, for instance in reporting jobs. Add --check to invest, redeem or switch to first run
+// the pre-flight checks of [wallet.Client.ValidateInvestment] and its siblings, and only send the
+// command if they pass.
+//
+// # Keys
+//
+// "wallet keys generate" creates an EC P-256 or RSA-4096 private key and the Certificate
+// Signing Request (CSR) to upload in Settings > API Keys of the Halogen Wallet, see
+// [wallet.GenerateKeyPair]. The private key is written with 0600 permissions, encrypted with
+// the passphrase read from --passphrase-file when given.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv))
+}
+
+// globals holds the flags shared by every subcommand.
+type globals struct {
+	keyID           string
+	keyFile         string
+	passphraseFile  string
+	credentialsFile string
+	environment     string
+	baseURL         string
+	output          string
+	timeout         time.Duration
+	debug           bool
+	yes             bool
+	dryRun          bool
+	readOnly        bool
+}
+
+func (g *globals) register(fs *flag.FlagSet, getenv func(string) string) {
+	fs.StringVar(&g.keyID, "key-id", "", "API key ID, defaulted to $WALLET_KEY_ID")
+	fs.StringVar(&g.keyFile, "key-file", "", "path of the PEM encoded private key, defaulted to $WALLET_KEY_FILE")
+	fs.StringVar(&g.passphraseFile, "key-passphrase-file", getenv("WALLET_KEY_PASSPHRASE_FILE"), "path of a file holding the passphrase of an encrypted private key")
+	fs.StringVar(&g.credentialsFile, "credentials", getenv("WALLET_CREDENTIALS"), "path of a JSON credentials file holding keyId and privateKeyFile or privateKey")
+	fs.StringVar(&g.environment, "env", getenv("WALLET_ENV"), "environment, either production or sandbox")
+	fs.StringVar(&g.baseURL, "base-url", getenv("WALLET_BASE_URL"), "server URL, overriding --env")
+	fs.StringVar(&g.output, "output", "table", "output format, one of table, json or yaml")
+	fs.StringVar(&g.output, "o", "table", "shorthand for --output")
+	fs.DurationVar(&g.timeout, "timeout", 30*time.Second, "timeout of the call, including retries")
+	fs.BoolVar(&g.debug, "debug", false, "log requests and responses")
+	fs.BoolVar(&g.yes, "yes", false, "send commands without asking for confirmation")
+	fs.BoolVar(&g.dryRun, "dry-run", false, "validate and sign the command, and log its payload instead of sending it")
+	readOnly, _ := strconv.ParseBool(getenv("WALLET_READ_ONLY"))
+	fs.BoolVar(&g.readOnly, "read-only", readOnly, "refuse to send commands, defaulted to $WALLET_READ_ONLY")
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, getenv func(string) string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return 0
+	}
+	cmd, rest := lookup(args)
+	if cmd == nil {
+		fmt.Fprintf(stderr, "wallet: unknown command %q\n\n", strings.Join(args[:min(2, len(args))], " "))
+		usage(stderr)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("wallet "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var g globals
+	g.register(fs, getenv)
+	var idempotencyKey string
+	if cmd.command {
+		fs.StringVar(&idempotencyKey, "idempotency-key", "", "idempotency key, to re-send a command whose outcome is unknown")
+	}
+	input, call := cmd.setup(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: wallet %s [flags]\n\n%s\n\nFlags:\n", cmd.name, cmd.summary)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(rest); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "wallet %s: unexpected argument %q\n", cmd.name, fs.Arg(0))
+		return 2
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for _, name := range cmd.required {
+		if !set[name] {
+			fmt.Fprintf(stderr, "wallet %s: --%s is required\n", cmd.name, name)
+			return 2
+		}
+	}
+	format, err := parseFormat(g.output)
+	if err != nil {
+		fmt.Fprintf(stderr, "wallet %s: %v\n", cmd.name, err)
+		return 2
+	}
+
+	if g.dryRun && !cmd.command {
+		fmt.Fprintf(stderr, "wallet %s: --dry-run only applies to commands\n", cmd.name)
+		return 2
+	}
+	if cmd.local {
+		output, err := call(ctx, nil)
+		if err != nil {
+			printError(stderr, cmd, err)
+			return 1
+		}
+		if err := format.write(stdout, output); err != nil {
+			fmt.Fprintf(stderr, "wallet %s: %v\n", cmd.name, err)
+			return 1
+		}
+		return 0
+	}
+	if cmd.command && g.readOnly {
+		fmt.Fprintf(stderr, "wallet %s: %v: command %s refused\n", cmd.name, wallet.ErrReadOnly, cmd.api)
+		return 1
+	}
+	if cmd.command && !g.yes && !g.dryRun {
+		ok, err := confirm(stdin, stderr, cmd, input)
+		if err != nil {
+			fmt.Fprintf(stderr, "wallet %s: %v\n", cmd.name, err)
+			return 1
+		}
+		if !ok {
+			fmt.Fprintln(stderr, "aborted")
+			return 1
+		}
+	}
+
+	client, err := newClient(&g, getenv, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "wallet %s: %v\n", cmd.name, err)
+		return 1
+	}
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	if idempotencyKey != "" {
+		ctx = wallet.WithIdempotencyKey(ctx, idempotencyKey)
+	}
+	output, err := call(ctx, client)
+	if err != nil {
+		printError(stderr, cmd, err)
+		return 1
+	}
+	if err := format.write(stdout, output); err != nil {
+		fmt.Fprintf(stderr, "wallet %s: %v\n", cmd.name, err)
+		return 1
+	}
+	return 0
+}
+
+// lookup returns the command named by the first one or two arguments, and the remaining arguments.
+func lookup(args []string) (*command, []string) {
+	if len(args) >= 2 {
+		if cmd := findCommand(args[0] + " " + args[1]); cmd != nil {
+			return cmd, args[2:]
+		}
+	}
+	return findCommand(args[0]), args[1:]
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: wallet <command> [flags]\n\nCommands:\n")
+	width := 0
+	for _, cmd := range commands {
+		width = max(width, len(cmd.name))
+	}
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun \"wallet <command> -h\" for the flags of a command.\n")
+}
+
+// confirm prints the command about to be sent and reads the answer from stdin.
+func confirm(stdin io.Reader, stderr io.Writer, cmd *command, input any) (bool, error) {
+	payload, err := json.MarshalIndent(input, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	fmt.Fprintf(stderr, "About to send command %q with payload:\n%s\nProceed? [y/N]: ", cmd.api, payload)
+	answer, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func printError(w io.Writer, cmd *command, err error) {
+	var werr wallet.Error
+	if !errors.As(err, &werr) {
+		fmt.Fprintf(w, "wallet %s: %v\n", cmd.name, err)
+		return
+	}
+	fmt.Fprintf(w, "wallet %s: %s (%s)\n", cmd.name, werr.Error(), werr.Code)
+	for _, d := range werr.Details {
+		fmt.Fprintf(w, "  %s: %s\n", d.Field, d.Message)
+	}
+	if werr.RequestID != "" {
+		fmt.Fprintf(w, "request ID: %s\n", werr.RequestID)
+	}
+}
+
+// newClient returns the client of the subcommand. With --dry-run, commands are validated and
+// signed, and their redacted payload and token claims are logged to stderr instead of being sent.
+func newClient(g *globals, getenv func(string) string, stderr io.Writer) (*wallet.Client, error) {
+	loader, err := credentialsLoader(g, getenv)
+	if err != nil {
+		return nil, err
+	}
+	o := &wallet.Options{
+		CredentialsLoaderFunc: loader,
+		PassphraseFunc:        passphraseLoader(g.passphraseFile),
+		Environment:           wallet.Environment(g.environment),
+		BaseURL:               g.baseURL,
+		Debug:                 g.debug,
+		ReadOnly:              g.readOnly,
+		DryRun:                g.dryRun,
+	}
+	if g.dryRun && !g.debug {
+		o.Logger = slog.New(slog.NewJSONHandler(stderr, nil))
+	}
+	if g.baseURL != "" && g.environment == "" {
+		o.Environment = wallet.EnvironmentCustom
+	}
+	return wallet.New(o), nil
+}
+
+// credentialsFile is the format of the file given by --credentials.
+type credentialsFile struct {
+	KeyID          string `json:"keyId"`
+	PrivateKeyFile string `json:"privateKeyFile"`
+	PrivateKey     string `json:"privateKey"`
+}
+
+// credentialsLoader returns a loader reading the private key for every request, so that the
+// client clears it from memory after each of them.
+func credentialsLoader(g *globals, getenv func(string) string) (func() (string, []byte, error), error) {
+	if g.keyID != "" || g.keyFile != "" {
+		if g.keyID == "" || g.keyFile == "" {
+			return nil, fmt.Errorf("--key-id and --key-file must be set together")
+		}
+		return fileLoader(g.keyID, g.keyFile), nil
+	}
+	if keyID := getenv("WALLET_KEY_ID"); keyID != "" {
+		if path := getenv("WALLET_KEY_FILE"); path != "" {
+			return fileLoader(keyID, path), nil
+		}
+		if key := getenv("WALLET_PRIVATE_KEY"); key != "" {
+			return func() (string, []byte, error) {
+				return keyID, []byte(key), nil
+			}, nil
+		}
+		return nil, fmt.Errorf("WALLET_KEY_ID is set without WALLET_KEY_FILE or WALLET_PRIVATE_KEY")
+	}
+	if g.credentialsFile != "" {
+		b, err := os.ReadFile(g.credentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		var f credentialsFile
+		if err := json.Unmarshal(b, &f); err != nil {
+			return nil, fmt.Errorf("invalid credentials file %s: %v", g.credentialsFile, err)
+		}
+		switch {
+		case f.KeyID == "":
+			return nil, fmt.Errorf("credentials file %s has no keyId", g.credentialsFile)
+		case f.PrivateKeyFile != "":
+			return fileLoader(f.KeyID, f.PrivateKeyFile), nil
+		case f.PrivateKey != "":
+			return func() (string, []byte, error) {
+				return f.KeyID, []byte(f.PrivateKey), nil
+			}, nil
+		default:
+			return nil, fmt.Errorf("credentials file %s has neither privateKeyFile nor privateKey", g.credentialsFile)
+		}
+	}
+	return nil, fmt.Errorf("credentials are not set. Use --key-id and --key-file, WALLET_KEY_ID, or --credentials")
+}
+
+// passphraseLoader returns a function reading the passphrase of an encrypted private key from
+// path, without its trailing newline, or nil when path is empty.
+func passphraseLoader(path string) func(keyID string) ([]byte, error) {
+	if path == "" {
+		return nil
+	}
+	return func(keyID string) ([]byte, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.TrimRight(b, "\r\n"), nil
+	}
+}
+
+func fileLoader(keyID string, path string) func() (string, []byte, error) {
+	return func() (string, []byte, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		return keyID, b, nil
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+// runCLI runs the CLI against srv with credentials passed through the environment.
+func runCLI(t *testing.T, srv *wallettest.Server, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	keyID, privateKeyPEM := srv.GenerateKey()
+	env := map[string]string{
+		"WALLET_KEY_ID":      keyID,
+		"WALLET_PRIVATE_KEY": string(privateKeyPEM),
+		"WALLET_BASE_URL":    srv.URL,
+	}
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr, func(k string) string {
+		return env[k]
+	})
+	return code, stdout.String(), stderr.String()
+}
+
+func TestQueryOutputs(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+
+	code, stdout, stderr := runCLI(t, srv, "", "balance", "--account", wallettest.SingleAccountID)
+	if code != 0 || !strings.Contains(stdout, "UNITS") || !strings.Contains(stdout, "8000") {
+		t.Fatalf("code=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+	code, stdout, _ = runCLI(t, srv, "", "accounts", "list", "-o", "json")
+	var accounts struct {
+		Accounts []json.RawMessage `json:"accounts"`
+	}
+	if code != 0 || json.Unmarshal([]byte(stdout), &accounts) != nil || len(accounts.Accounts) != 2 {
+		t.Fatalf("code=%d stdout=%q", code, stdout)
+	}
+	code, stdout, _ = runCLI(t, srv, "", "referral", "get", "--output", "yaml")
+	if code != 0 || !strings.Contains(stdout, "referredClientsCount: ") {
+		t.Fatalf("code=%d stdout=%q", code, stdout)
+	}
+	if code, _, stderr := runCLI(t, srv, "", "balance"); code != 2 || !strings.Contains(stderr, "--account is required") {
+		t.Fatalf("code=%d stderr=%q", code, stderr)
+	}
+}
+
+func TestCommandConfirmation(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	args := []string{"currencies", "set", "--currency", "USD"}
+
+	code, _, stderr := runCLI(t, srv, "", append(args, "--dry-run")...)
+	if code != 0 || len(srv.Calls()) != 0 {
+		t.Fatalf("code=%d stderr=%q, expected the dry run not to send the command", code, stderr)
+	}
+	for _, want := range []string{"update_display_currency", `\"displayCurrency\":\"USD\"`, "bodyHash"} {
+		if !strings.Contains(stderr, want) {
+			t.Fatalf("expected the signed command to be logged with %s, got %q", want, stderr)
+		}
+	}
+	if code, _, stderr := runCLI(t, srv, "", "balance", "--account", wallettest.SingleAccountID, "--dry-run"); code != 2 || !strings.Contains(stderr, "only applies to commands") {
+		t.Fatalf("code=%d stderr=%q, expected --dry-run to be rejected for queries", code, stderr)
+	}
+	if code, _, stderr := runCLI(t, srv, "n\n", args...); code != 1 || !strings.Contains(stderr, "aborted") || len(srv.Calls()) != 0 {
+		t.Fatalf("code=%d stderr=%q, expected the command to be aborted", code, stderr)
+	}
+	if code, _, stderr := runCLI(t, srv, "y\n", args...); code != 0 || len(srv.Calls()) != 1 {
+		t.Fatalf("code=%d stderr=%q, expected the command to be sent", code, stderr)
+	}
+	if code, _, stderr := runCLI(t, srv, "", append(args, "--yes")...); code != 0 || len(srv.Calls()) != 2 {
+		t.Fatalf("code=%d stderr=%q, expected the command to be sent", code, stderr)
+	}
+	if code, _, stderr := runCLI(t, srv, "", append(args, "--yes", "--read-only")...); code != 1 || !strings.Contains(stderr, "read-only") || len(srv.Calls()) != 2 {
+		t.Fatalf("code=%d stderr=%q, expected the command to be refused", code, stderr)
+	}
+}
+
+func TestCredentialsFile(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	keyID, privateKeyPEM := srv.GenerateKey()
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key.pem")
+	credentials := filepath.Join(dir, "credentials.json")
+	if err := os.WriteFile(keyFile, privateKeyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(credentialsFile{KeyID: keyID, PrivateKeyFile: keyFile})
+	if err := os.WriteFile(credentials, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"statement", "download", "--base-url", srv.URL, "--credentials", credentials,
+		"--account", wallettest.SingleAccountID, "--from", "2025-01-01", "--to", "2025-06-30", "--out", filepath.Join(dir, "statement.pdf")}
+	if code := run(context.Background(), args, nil, &stdout, &stderr, func(string) string { return "" }); code != 0 {
+		t.Fatalf("code=%d stderr=%q", code, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "statement.pdf")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommandCheck(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	args := []string{"redeem", "--yes", "--check", "--account", wallettest.SingleAccountID, "--fund", wallettest.BitcoinFundID, "--class", "1"}
+
+	code, _, stderr := runCLI(t, srv, "", append(args, "--units", "9000")...)
+	if code != 1 || !strings.Contains(stderr, "ErrInsufficientBalance") {
+		t.Fatalf("code=%d stderr=%q, expected the checks to fail", code, stderr)
+	}
+	for _, call := range srv.Calls() {
+		if call.Kind == "command" {
+			t.Fatalf("expected no command to be sent, got %s", call.Name)
+		}
+	}
+	if code, _, stderr := runCLI(t, srv, "", append(args, "--units", "100")...); code != 0 || srv.Calls()[len(srv.Calls())-1].Name != "create_redemption_request" {
+		t.Fatalf("code=%d stderr=%q, expected the command to be sent", code, stderr)
+	}
+}
+
+func TestCommandKeysGenerate(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	dir := t.TempDir()
+	passphraseFile := filepath.Join(dir, "passphrase")
+	if err := os.WriteFile(passphraseFile, []byte("correct horse\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyFile, csrFile := filepath.Join(dir, "key.pem"), filepath.Join(dir, "csr.pem")
+	args := []string{"keys", "generate", "--key-out", keyFile, "--csr-out", csrFile, "--cn", "example.com", "--country", "MY", "--passphrase-file", passphraseFile, "-o", "json"}
+
+	code, stdout, stderr := runCLI(t, srv, "", args...)
+	if code != 0 || !strings.Contains(stdout, `"encrypted": true`) || len(srv.Calls()) != 0 {
+		t.Fatalf("code=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+	if info, err := os.Stat(keyFile); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected the private key to be written with 0600 permissions, got %v %v", info, err)
+	}
+	if b, err := os.ReadFile(keyFile); err != nil || !strings.Contains(string(b), "ENCRYPTED PRIVATE KEY") {
+		t.Fatalf("expected an encrypted private key, got %v", err)
+	}
+	if code, _, stderr := runCLI(t, srv, "", args...); code != 1 || !strings.Contains(stderr, "exists") {
+		t.Fatalf("expected the private key not to be overwritten, got code=%d stderr=%q", code, stderr)
+	}
+}
+
+func TestCommandEncryptedKey(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	dir := t.TempDir()
+	passphraseFile := filepath.Join(dir, "passphrase")
+	if err := os.WriteFile(passphraseFile, []byte("correct horse\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pair, err := wallet.GenerateKeyPair(&wallet.GenerateKeyPairInput{Passphrase: []byte("correct horse")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile, csrFile := filepath.Join(dir, "key.pem"), filepath.Join(dir, "csr.pem")
+	if err := pair.WriteFiles(keyFile, csrFile); err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(pair.CSRPEM)
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.RegisterKey("encrypted-key", csr.PublicKey)
+
+	env := map[string]string{"WALLET_KEY_ID": "encrypted-key", "WALLET_KEY_FILE": keyFile, "WALLET_BASE_URL": srv.URL}
+	getenv := func(k string) string { return env[k] }
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"banks", "list"}, strings.NewReader(""), &stdout, &stderr, getenv); code != 1 || !strings.Contains(stderr.String(), "encrypted") {
+		t.Fatalf("expected the encrypted key to be refused without passphrase, got code=%d stderr=%q", code, stderr.String())
+	}
+	env["WALLET_KEY_PASSPHRASE_FILE"] = passphraseFile
+	stderr.Reset()
+	if code := run(context.Background(), []string{"banks", "list"}, strings.NewReader(""), &stdout, &stderr, getenv); code != 0 {
+		t.Fatalf("code=%d stderr=%q", code, stderr.String())
+	}
+}
+
+func TestSave(t *testing.T) {
+	t.Chdir(t.TempDir())
+	out, err := save("", "../../.ssh/authorized_keys", []byte("statement"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Path != "authorized_keys" {
+		t.Fatalf("got path %q, want the base name of the filename", out.Path)
+	}
+	if _, err := save("", "authorized_keys", []byte("other")); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("expected an existing file not to be overwritten, got %v", err)
+	}
+	for _, filename := range []string{"", ".", "..", "/"} {
+		if _, err := save("", filename, []byte("statement")); err == nil {
+			t.Errorf("expected filename %q to be rejected", filename)
+		}
+	}
+	if _, err := save("statement.pdf", "", []byte("statement")); err != nil {
+		t.Fatal(err)
+	}
+}
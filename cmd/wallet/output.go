@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+type format string
+
+const (
+	formatTable format = "table"
+	formatJSON  format = "json"
+	formatYAML  format = "yaml"
+)
+
+func parseFormat(s string) (format, error) {
+	switch f := format(strings.ToLower(s)); f {
+	case formatTable, formatJSON, formatYAML:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported output %q. Valid output would be table, json or yaml", s)
+	}
+}
+
+func (f format) write(w io.Writer, v any) error {
+	switch f {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return writeYAML(w, b)
+	default:
+		return writeTable(w, v)
+	}
+}
+
+// writeTable prints the scalar fields of v as name/value pairs, then every slice of structs
+// field of v as a table with a column per scalar field.
+func writeTable(w io.Writer, v any) error {
+	rv := indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		_, err := fmt.Fprintln(w, formatScalar(rv))
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	var tables []reflect.StructField
+	for _, field := range reflect.VisibleFields(rv.Type()) {
+		name := jsonName(field)
+		if name == "" {
+			continue
+		}
+		ft, fv := field.Type, indirect(rv.FieldByIndex(field.Index))
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch {
+		case isScalar(ft):
+			fmt.Fprintf(tw, "%s\t%s\n", strings.ToUpper(name), formatScalar(fv))
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Uint8:
+			// documents are written to files, see save
+		case ft.Kind() == reflect.Slice && isScalar(ft.Elem()):
+			values := make([]string, fv.Len())
+			for i := range values {
+				values[i] = formatScalar(indirect(fv.Index(i)))
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", strings.ToUpper(name), strings.Join(values, ", "))
+		case fv.IsValid() && (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Struct):
+			tables = append(tables, field)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for i, field := range tables {
+		fv := indirect(rv.FieldByIndex(field.Index))
+		if fv.Kind() == reflect.Struct {
+			fv = reflect.ValueOf([]any{fv.Interface()})
+		}
+		if i > 0 || rv.NumField() > len(tables) {
+			fmt.Fprintf(w, "\n%s\n", strings.ToUpper(jsonName(field)))
+		}
+		if err := writeRows(w, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRows prints the slice rows as a table with a column per scalar field of its elements.
+func writeRows(w io.Writer, rows reflect.Value) error {
+	if rows.Len() == 0 {
+		_, err := fmt.Fprintln(w, "(none)")
+		return err
+	}
+	elemType := indirect(rows.Index(0)).Type()
+	if elemType.Kind() != reflect.Struct {
+		for i := 0; i < rows.Len(); i++ {
+			fmt.Fprintln(w, formatScalar(indirect(rows.Index(i))))
+		}
+		return nil
+	}
+	var columns []reflect.StructField
+	for _, field := range reflect.VisibleFields(elemType) {
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if jsonName(field) != "" && isScalar(ft) {
+			columns = append(columns, field)
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for i, column := range columns {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, strings.ToUpper(jsonName(column)))
+	}
+	fmt.Fprintln(tw)
+	for i := 0; i < rows.Len(); i++ {
+		row := indirect(rows.Index(i))
+		for j, column := range columns {
+			if j > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			if row.IsValid() {
+				fmt.Fprint(tw, formatScalar(indirect(row.FieldByIndex(column.Index))))
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+func isScalar(t reflect.Type) bool {
+	if t.Implements(textMarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Pointer:
+		return isScalar(t.Elem())
+	}
+	return false
+}
+
+func formatScalar(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// jsonName returns the JSON name of an exported field, or "" when it is not encoded.
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() || field.Anonymous {
+		return ""
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// writeYAML converts the JSON document b to YAML, keeping the order of object keys.
+func writeYAML(w io.Writer, b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	v, err := decodeOrdered(dec)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	encodeYAML(&buf, v, 0)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+type orderedObject []keyValue
+
+type keyValue struct {
+	key   string
+	value any
+}
+
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := orderedObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, keyValue{key: key.(string), value: value})
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := dec.Token()
+		return arr, err
+	default:
+		return tok, nil
+	}
+}
+
+func encodeYAML(buf *bytes.Buffer, v any, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch v := v.(type) {
+	case orderedObject:
+		if len(v) == 0 {
+			buf.WriteString(pad + "{}\n")
+			return
+		}
+		for _, kv := range v {
+			buf.WriteString(pad + yamlScalar(kv.key) + ":")
+			writeYAMLValue(buf, kv.value, indent)
+		}
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range v {
+			buf.WriteString(pad + "-")
+			writeYAMLValue(buf, item, indent)
+		}
+	default:
+		buf.WriteString(pad + yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLValue writes the value following a key or a list dash.
+func writeYAMLValue(buf *bytes.Buffer, v any, indent int) {
+	switch v := v.(type) {
+	case orderedObject:
+		if len(v) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteString("\n")
+		encodeYAML(buf, v, indent+1)
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteString("\n")
+		encodeYAML(buf, v, indent+1)
+	default:
+		buf.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		if needsQuotes(v) {
+			return strconv.Quote(v)
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// needsQuotes reports whether s would not be read back as the same string when left plain.
+func needsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	return strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.ContainsAny(s, "\n\t\\\"")
+}
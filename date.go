@@ -0,0 +1,227 @@
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// MalaysiaTime is the time zone of Halogen's business days, Asia/Kuala_Lumpur. Malaysia has
+// observed UTC+8 without daylight saving time since 1982, so the zone is fixed and does not
+// depend on the time zone database of the host.
+var MalaysiaTime = time.FixedZone("MYT", 8*60*60)
+
+const dateLayout = "2006-01-02"
+
+// Date is a calendar day in Malaysia time, such as the dates of a statement or the date of a
+// performance point. It is marshaled as "2006-01-02".
+//
+// The zero value is the zero date, marshaled as an empty string and omitted from inputs.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate returns the date of year, month and day, normalized like [time.Date]: October 32
+// becomes November 1.
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, MalaysiaTime))
+}
+
+// DateOf returns the day t falls on in Malaysia time. For instance 2025-01-01T20:00:00Z is
+// 2025-01-02 in Malaysia.
+func DateOf(t time.Time) Date {
+	y, m, d := t.In(MalaysiaTime).Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// Today returns the current day in Malaysia time.
+func Today() Date {
+	return DateOf(time.Now())
+}
+
+// ParseDate parses s as "2006-01-02". Timestamps accepted by [ParseTimestamp] are accepted too,
+// and return the day they fall on in Malaysia time.
+func ParseDate(s string) (Date, error) {
+	if t, err := time.ParseInLocation(dateLayout, s, MalaysiaTime); err == nil {
+		return DateOf(t), nil
+	}
+	ts, err := ParseTimestamp(s)
+	if err != nil {
+		return Date{}, fmt.Errorf("wallet: ParseDate: invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return DateOf(ts.Time), nil
+}
+
+// MustParseDate is like [ParseDate] but panics if s is invalid. It is meant for constants.
+func MustParseDate(s string) Date {
+	d, err := ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// IsZero reports whether d is the zero date. It makes fields tagged with omitzero omitted when unset.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Start returns the first instant of d in Malaysia time.
+func (d Date) Start() time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, MalaysiaTime)
+}
+
+// AddDays returns d plus n days, n may be negative.
+func (d Date) AddDays(n int) Date {
+	return NewDate(d.Year, d.Month, d.Day+n)
+}
+
+// Compare returns -1, 0 or +1 depending on whether d is before, equal to or after e.
+func (d Date) Compare(e Date) int {
+	return d.Start().Compare(e.Start())
+}
+
+// Before reports whether d is before e.
+func (d Date) Before(e Date) bool {
+	return d.Compare(e) < 0
+}
+
+// After reports whether d is after e.
+func (d Date) After(e Date) bool {
+	return d.Compare(e) > 0
+}
+
+// Weekday returns the day of the week of d.
+func (d Date) Weekday() time.Weekday {
+	return d.Start().Weekday()
+}
+
+// IsBusinessDay reports whether d is a weekday, Monday to Friday. Public holidays are not
+// accounted for: the server remains the authority, see [ErrActionOutsideFundHours].
+func (d Date) IsBusinessDay() bool {
+	wd := d.Weekday()
+	return wd != time.Saturday && wd != time.Sunday
+}
+
+// NextBusinessDay returns the first business day after d.
+func (d Date) NextBusinessDay() Date {
+	next := d.AddDays(1)
+	for !next.IsBusinessDay() {
+		next = next.AddDays(1)
+	}
+	return next
+}
+
+// String returns d as "2006-01-02", or "" for the zero date.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. An empty text is the zero date.
+func (d *Date) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*d = Date{}
+		return nil
+	}
+	v, err := ParseDate(string(b))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// timestampLayouts lists the formats of timestamps sent by the server. Layouts without a time
+// zone are in Malaysia time.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	dateLayout,
+}
+
+// Timestamp is an instant, such as the creation time of a request. It is marshaled in
+// RFC 3339 format in UTC, for instance "2025-01-01T02:00:00Z".
+//
+// The zero value is the zero time, marshaled as an empty string and omitted from inputs. Use
+// [DateOf] for the business day of a timestamp.
+type Timestamp struct {
+	time.Time
+}
+
+// ParseTimestamp parses s in RFC 3339 format. Timestamps without a time zone, such as
+// "2025-01-01 10:00:00", and dates are in Malaysia time.
+func ParseTimestamp(s string) (Timestamp, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, MalaysiaTime); err == nil {
+			return Timestamp{Time: t}, nil
+		}
+	}
+	return Timestamp{}, fmt.Errorf("wallet: ParseTimestamp: invalid timestamp %q, expected RFC 3339", s)
+}
+
+// String returns ts in RFC 3339 format in UTC, or "" for the zero time.
+func (ts Timestamp) String() string {
+	if ts.IsZero() {
+		return ""
+	}
+	return ts.UTC().Format(time.RFC3339Nano)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (ts Timestamp) MarshalText() ([]byte, error) {
+	return []byte(ts.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. An empty text is the zero time.
+func (ts *Timestamp) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*ts = Timestamp{}
+		return nil
+	}
+	v, err := ParseTimestamp(string(b))
+	if err != nil {
+		return err
+	}
+	*ts = v
+	return nil
+}
+
+// MarshalJSON encodes ts as a JSON string with [Timestamp.MarshalText].
+func (ts Timestamp) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + ts.String() + `"`), nil
+}
+
+// UnmarshalJSON decodes a JSON string with [Timestamp.UnmarshalText]. null is ignored.
+func (ts *Timestamp) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return fmt.Errorf("wallet: Timestamp: expected a JSON string, got %s", b)
+	}
+	return ts.UnmarshalText(b[1 : len(b)-1])
+}
+
+// checkDateRange appends an [ErrInvalidDateRange] detail to details when from is after to.
+func checkDateRange(details []ErrorDetail, from Date, to Date) []ErrorDetail {
+	if from.IsZero() || to.IsZero() || !from.After(to) {
+		return details
+	}
+	return append(details, ErrorDetail{
+		Field:   "fromDate",
+		Code:    ErrInvalidDateRange,
+		Message: fmt.Sprintf("fromDate %s must not be after toDate %s", from, to),
+	})
+}
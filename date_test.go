@@ -0,0 +1,107 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Date
+	}{
+		{"2025-01-31", NewDate(2025, time.January, 31)},
+		// 20:00 UTC is 04:00 the next day in Malaysia
+		{"2025-01-31T20:00:00Z", NewDate(2025, time.February, 1)},
+		{"2025-01-31T20:00:00+08:00", NewDate(2025, time.January, 31)},
+		{"2025-01-31 23:59:59", NewDate(2025, time.January, 31)},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"31/01/2025", "2025-02-30", "2025-1-1"} {
+		if _, err := ParseDate(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2025, time.January, 1, 2, 0, 0, 0, time.UTC)
+	for _, in := range []string{"2025-01-01T02:00:00Z", "2025-01-01T10:00:00+08:00", "2025-01-01 10:00:00", "2025-01-01T10:00:00.000"} {
+		ts, err := ParseTimestamp(in)
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if !ts.Equal(want) {
+			t.Errorf("%s: got %s, want %s", in, ts, want)
+		}
+		if got := ts.String(); got != "2025-01-01T02:00:00Z" {
+			t.Errorf("%s: got %s, want 2025-01-01T02:00:00Z", in, got)
+		}
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	var v struct {
+		Date      Date      `json:"date,omitzero"`
+		CreatedAt Timestamp `json:"createdAt,omitzero"`
+		ToDate    *Date     `json:"toDate,omitempty"`
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{}` {
+		t.Fatalf("got %s, want zero values omitted", b)
+	}
+	if err := json.Unmarshal([]byte(`{"date":"2025-03-01","createdAt":"2025-03-01T01:02:03.5+08:00","toDate":null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	b, err = json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"date":"2025-03-01","createdAt":"2025-02-28T17:02:03.5Z"}`; string(b) != want {
+		t.Fatalf("got %s, want %s", b, want)
+	}
+	if DateOf(v.CreatedAt.Time) != v.Date {
+		t.Fatalf("got %s, want the Malaysia date %s", DateOf(v.CreatedAt.Time), v.Date)
+	}
+}
+
+func TestDateBusinessDays(t *testing.T) {
+	friday := NewDate(2025, time.January, 31)
+	if !friday.IsBusinessDay() || friday.AddDays(1).IsBusinessDay() {
+		t.Fatal("expected Friday to be a business day and Saturday not to be")
+	}
+	if got, want := friday.NextBusinessDay(), NewDate(2025, time.February, 3); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if !friday.Before(friday.AddDays(1)) || friday.Compare(MustParseDate("2025-01-31")) != 0 {
+		t.Fatal("unexpected comparison")
+	}
+}
+
+func TestDateRangeValidation(t *testing.T) {
+	err := validate("get_client_account_statement", &GetClientAccountStatementInput{
+		FromDate: NewDate(2025, time.February, 1),
+		ToDate:   NewDate(2025, time.January, 1),
+		Format:   DocumentFormatPDF,
+	})
+	if !errors.Is(err, Error{Code: ErrInvalidDateRange}) || !IsValidation(err) {
+		t.Fatalf("got %v, want %s", err, ErrInvalidDateRange)
+	}
+	from, to := NewDate(2025, time.January, 1), NewDate(2025, time.January, 1)
+	if err := validate("list_client_account_requests", &ListClientAccountRequestsInput{FromDate: &from, ToDate: &to}); err != nil {
+		t.Fatalf("expected a single day range to be valid, got %v", err)
+	}
+}
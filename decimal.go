@@ -0,0 +1,322 @@
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// CurrencyScale is the number of decimal places of currency amounts, for instance 1000.50.
+	CurrencyScale int32 = 2
+
+	// UnitScale is the number of decimal places of fund units, for instance 812.3456.
+	UnitScale int32 = 4
+
+	// MaxDecimalScale bounds the number of decimal places, and of trailing zeros given by an
+	// exponent, accepted by [ParseDecimal] and [NewDecimal]. Without it, a value such as
+	// "1e100000000" in a response would take minutes and gigabytes to decode.
+	MaxDecimalScale int32 = 1000
+)
+
+// RoundingMode specifies how [Decimal.Round] and [Decimal.Div] drop digits.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest value, and away from zero on ties: 1.25 becomes 1.3.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest value, and to the even digit on ties: 1.25 becomes 1.2.
+	RoundHalfEven
+	// RoundDown rounds toward zero, truncating the dropped digits: 1.29 becomes 1.2.
+	RoundDown
+	// RoundUp rounds away from zero: 1.21 becomes 1.3.
+	RoundUp
+	// RoundFloor rounds toward negative infinity: -1.21 becomes -1.3.
+	RoundFloor
+	// RoundCeiling rounds toward positive infinity: -1.29 becomes -1.2.
+	RoundCeiling
+)
+
+// Decimal is an exact decimal number, used for amounts, units, prices and percentages.
+//
+// A Decimal keeps the digits it was parsed from: "1000.50" is marshaled back as 1000.50, so
+// values read from the server are reported exactly as the server sent them. Arithmetic is
+// exact except for [Decimal.Div], which rounds to the requested scale.
+//
+// The zero value is 0. Decimals are immutable and safe to copy. Use [Decimal.Equal] or
+// [Decimal.Cmp] to compare values, since == also compares the number of decimal places.
+//
+// Code using float64 amounts may convert with [NewDecimalFromFloat] and [Decimal.Float64].
+type Decimal struct {
+	// coef is the unscaled value, nil for zero. It is never modified once set.
+	coef *big.Int
+	// scale is the number of digits after the decimal point, never negative.
+	scale int32
+}
+
+// NewDecimal returns coef × 10^-scale, for instance NewDecimal(1050, 2) is 10.50. It panics if
+// |scale| is greater than [MaxDecimalScale].
+func NewDecimal(coef int64, scale int32) Decimal {
+	if scale > MaxDecimalScale || scale < -MaxDecimalScale {
+		panic(fmt.Sprintf("wallet: NewDecimal: scale %d out of range", scale))
+	}
+	return newDecimal(big.NewInt(coef), scale)
+}
+
+// NewDecimalFromInt returns i as a Decimal.
+func NewDecimalFromInt(i int64) Decimal {
+	return NewDecimal(i, 0)
+}
+
+// NewDecimalFromFloat returns the shortest decimal representation of f, for instance 0.1 for
+// the float64 closest to 0.1. It panics if f is NaN or infinite.
+func NewDecimalFromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		panic(fmt.Sprintf("wallet: NewDecimalFromFloat: %v is not a number", f))
+	}
+	d, err := ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// ParseDecimal parses s, such as "1000.50", "-0.25" or "1.5e3". It returns an error if s has
+// more than [MaxDecimalScale] decimal places, or an exponent adding more than
+// [MaxDecimalScale] zeros.
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exponent := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		mantissa = s[:i]
+		exponent, err = strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("wallet: ParseDecimal: invalid exponent in %q", s)
+		}
+	}
+	sign := ""
+	if mantissa != "" && (mantissa[0] == '+' || mantissa[0] == '-') {
+		sign, mantissa = mantissa[:1], mantissa[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	if intPart+fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Decimal{}, fmt.Errorf("wallet: ParseDecimal: invalid decimal %q", s)
+	}
+	// the scale is checked before coef is scaled, since 10^-scale is computed eagerly.
+	scale := int64(len(fracPart)) - exponent
+	if scale > int64(MaxDecimalScale) || scale < -int64(MaxDecimalScale) {
+		return Decimal{}, fmt.Errorf("wallet: ParseDecimal: exponent or decimal places out of range in %q", s)
+	}
+	coef, _ := new(big.Int).SetString(sign+intPart+fracPart, 10)
+	return newDecimal(coef, int32(scale)), nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// MustParseDecimal is like [ParseDecimal] but panics if s is invalid. It is meant for constants.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// newDecimal returns coef × 10^-scale, taking ownership of coef.
+func newDecimal(coef *big.Int, scale int32) Decimal {
+	if scale < 0 {
+		coef.Mul(coef, pow10(-scale))
+		scale = 0
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// int returns the unscaled value, never nil.
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescale returns the unscaled value of d at scale, which must be >= d.scale.
+func (d Decimal) rescale(scale int32) *big.Int {
+	return new(big.Int).Mul(d.int(), pow10(scale-d.scale))
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero reports whether d is 0. It makes fields tagged with omitzero omitted when 0.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp returns -1, 0 or +1 depending on whether d is lower than, equal to or greater than e.
+func (d Decimal) Cmp(e Decimal) int {
+	scale := max(d.scale, e.scale)
+	return d.rescale(scale).Cmp(e.rescale(scale))
+}
+
+// Equal reports whether d and e are the same number, regardless of their scale.
+func (d Decimal) Equal(e Decimal) bool {
+	return d.Cmp(e) == 0
+}
+
+// Add returns d + e.
+func (d Decimal) Add(e Decimal) Decimal {
+	scale := max(d.scale, e.scale)
+	return newDecimal(new(big.Int).Add(d.rescale(scale), e.rescale(scale)), scale)
+}
+
+// Sub returns d - e.
+func (d Decimal) Sub(e Decimal) Decimal {
+	scale := max(d.scale, e.scale)
+	return newDecimal(new(big.Int).Sub(d.rescale(scale), e.rescale(scale)), scale)
+}
+
+// Mul returns d × e.
+func (d Decimal) Mul(e Decimal) Decimal {
+	return newDecimal(new(big.Int).Mul(d.int(), e.int()), d.scale+e.scale)
+}
+
+// Div returns d ÷ e rounded to scale digits after the decimal point. It panics if e is 0.
+func (d Decimal) Div(e Decimal, scale int32, mode RoundingMode) Decimal {
+	if e.IsZero() {
+		panic("wallet: Decimal.Div: division by zero")
+	}
+	// d / e = (dc / 10^ds) / (ec / 10^es) = dc × 10^(scale + es - ds) / ec / 10^scale
+	num, den := d.int(), e.int()
+	if shift := scale + e.scale - d.scale; shift >= 0 {
+		num = new(big.Int).Mul(num, pow10(shift))
+	} else {
+		den = new(big.Int).Mul(den, pow10(-shift))
+	}
+	return newDecimal(quoRound(num, den, mode), scale)
+}
+
+// Round returns d rounded to scale digits after the decimal point, for instance to
+// [CurrencyScale] or [UnitScale]. The result always has scale digits, padded with zeros.
+func (d Decimal) Round(scale int32, mode RoundingMode) Decimal {
+	if scale >= d.scale {
+		return newDecimal(d.rescale(scale), scale)
+	}
+	return newDecimal(quoRound(d.int(), pow10(d.scale-scale), mode), scale)
+}
+
+// quoRound returns num ÷ den rounded with mode.
+func quoRound(num *big.Int, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	negative := num.Sign()*den.Sign() < 0
+	// half compares the remainder with half of the divisor: -1 below, 0 on ties, +1 above.
+	half := new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(new(big.Int).Abs(den))
+	var away bool
+	switch mode {
+	case RoundHalfUp:
+		away = half >= 0
+	case RoundHalfEven:
+		away = half > 0 || half == 0 && q.Bit(0) == 1
+	case RoundDown:
+		away = false
+	case RoundUp:
+		away = true
+	case RoundFloor:
+		away = negative
+	case RoundCeiling:
+		away = !negative
+	}
+	if !away {
+		return q
+	}
+	if negative {
+		return q.Sub(q, big.NewInt(1))
+	}
+	return q.Add(q, big.NewInt(1))
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return newDecimal(new(big.Int).Neg(d.int()), d.scale)
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	return newDecimal(new(big.Int).Abs(d.int()), d.scale)
+}
+
+// Float64 returns the float64 closest to d, for code still using float64 amounts.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns d in plain notation with all its decimal places, for instance "1000.50".
+func (d Decimal) String() string {
+	s := d.int().String()
+	if d.scale == 0 {
+		return s
+	}
+	sign := ""
+	if s[0] == '-' {
+		sign, s = "-", s[1:]
+	}
+	if pad := int(d.scale) + 1 - len(s); pad > 0 {
+		s = strings.Repeat("0", pad) + s
+	}
+	return sign + s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (d *Decimal) UnmarshalText(b []byte) error {
+	v, err := ParseDecimal(string(b))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// MarshalJSON encodes d as a JSON number with all its decimal places.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or string, keeping its exact digits. null is ignored.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	if len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"' {
+		b = b[1 : len(b)-1]
+	}
+	return d.UnmarshalText(b)
+}
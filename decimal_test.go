@@ -0,0 +1,100 @@
+package wallet
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		Amount Decimal  `json:"amount"`
+		Price  Decimal  `json:"price"`
+		Units  Decimal  `json:"units,omitzero"`
+		Fee    *Decimal `json:"fee"`
+	}
+	if err := json.Unmarshal([]byte(`{"amount":1000.50,"price":"0.12345678","fee":null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"amount":1000.50,"price":0.12345678,"fee":null}`; string(b) != want {
+		t.Fatalf("got %s, want %s", b, want)
+	}
+	if err := json.Unmarshal([]byte(`{"amount":"1,000"}`), &v); err == nil {
+		t.Fatal("expected an invalid decimal to be rejected")
+	}
+}
+
+func TestDecimalScaleLimit(t *testing.T) {
+	for _, s := range []string{"1e100000000", "1e-100000000", "1e2147483647", "1e1001", "0." + strings.Repeat("1", 1001)} {
+		var d Decimal
+		if err := json.Unmarshal([]byte(`"`+s+`"`), &d); err == nil {
+			t.Errorf("expected %.20s to be rejected", s)
+		}
+	}
+	for _, s := range []string{"1e1000", "1e-1000", "0." + strings.Repeat("1", 1000)} {
+		if _, err := ParseDecimal(s); err != nil {
+			t.Errorf("%.20s: %v", s, err)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	d := MustParseDecimal
+	tests := []struct {
+		got  Decimal
+		want string
+	}{
+		{d("0.1").Add(d("0.2")), "0.3"},
+		{d("1000.50").Sub(d("0.75")), "999.75"},
+		{d("8000").Mul(d("1.25")), "10000.00"},
+		{d("10").Div(d("3"), UnitScale, RoundDown), "3.3333"},
+		{d("-10").Div(d("3"), CurrencyScale, RoundHalfUp), "-3.33"},
+		{d("1.5e3"), "1500"},
+		{d("-0.005").Neg(), "0.005"},
+		{NewDecimal(1050, 2), "10.50"},
+		{NewDecimalFromFloat(0.1), "0.1"},
+	}
+	for _, tt := range tests {
+		if tt.got.String() != tt.want {
+			t.Errorf("got %s, want %s", tt.got, tt.want)
+		}
+	}
+	if !d("1.50").Equal(d("1.5")) || d("1.5").Cmp(d("1.49")) != 1 || !d("0.00").IsZero() {
+		t.Error("unexpected comparison")
+	}
+	if f := d("1000.25").Float64(); f != 1000.25 {
+		t.Errorf("got %v, want 1000.25", f)
+	}
+}
+
+func TestDecimalRound(t *testing.T) {
+	tests := []struct {
+		value string
+		mode  RoundingMode
+		want  string
+	}{
+		{"1.25", RoundHalfUp, "1.3"},
+		{"1.25", RoundHalfEven, "1.2"},
+		{"1.35", RoundHalfEven, "1.4"},
+		{"-1.25", RoundHalfUp, "-1.3"},
+		{"1.29", RoundDown, "1.2"},
+		{"1.21", RoundUp, "1.3"},
+		{"-1.21", RoundFloor, "-1.3"},
+		{"-1.29", RoundCeiling, "-1.2"},
+		{"1.2", RoundHalfUp, "1.2"},
+		{"-0.04", RoundHalfUp, "0.0"},
+		{"-0.06", RoundHalfUp, "-0.1"},
+	}
+	for _, tt := range tests {
+		if got := MustParseDecimal(tt.value).Round(1, tt.mode).String(); got != tt.want {
+			t.Errorf("%s rounded with mode %d: got %s, want %s", tt.value, tt.mode, got, tt.want)
+		}
+	}
+	if got := MustParseDecimal("7").Round(CurrencyScale, RoundHalfUp).String(); got != "7.00" {
+		t.Errorf("got %s, want 7.00", got)
+	}
+}
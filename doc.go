@@ -15,16 +15,140 @@
 //   - `bodyHash`: Hex-encoded SHA-256 hash of the request body
 //   - `uri`: The request URI (for example `/query` or `/command`)
 //
-// The JWT header contains `alg` (set to `ES256` or `RS256` depending on the private key)
-// and `typ: "JWT"`.
+// The JWT header contains `alg` (set according to the private key) and `typ: "JWT"`.
 //
-// The token is then signed using either:
+// The token is then signed using one of:
 //
-//   - ES256 (ECDSA with P-256 curve) when an EC private key is provided
-//   - RS256 (RSA) when an RSA private key is provided
+//   - ES256 (ECDSA with P-256 curve) when an EC P-256 private key is provided
+//   - ES384 (ECDSA with P-384 curve) when an EC P-384 private key is provided
+//   - RS256 (RSA PKCS #1 v1.5) when an RSA private key is provided
+//   - PS256 (RSA-PSS) when an RSA private key is provided and [Options.Algorithm] is set to [AlgorithmPS256]
+//   - EdDSA (Ed25519) when an Ed25519 PKCS #8 private key is provided
+//
+// An algorithm that does not match the key, such as ES256 with a P-384 key, is reported as an
+// error before any request is sent.
 //
 // You do not need to manually generate or sign tokens. The client handles this automatically
 // when you provide credentials via [Client.SetCredentials] or [Client.Options.CredentialsLoaderFunc].
+// Both parse the private key for every request. Use [Client.SetCachedCredentials] instead to parse
+// it once and keep it in memory, which is faster when sending many requests.
+//
+// The private key may be an encrypted PKCS #8 key, in an "ENCRYPTED PRIVATE KEY" PEM block, such
+// as the keys encrypted by "openssl pkcs8 -topk8" or [GenerateKeyPair]. Set
+// [Options.PassphraseFunc] to return its passphrase, which is cleared from memory along with the
+// decrypted key once the key is parsed:
+//
+//	client := wallet.New(&wallet.Options{
+//		PassphraseFunc: func(keyID string) ([]byte, error) {
+//			return []byte(os.Getenv("HALOGEN_WALLET_KEY_PASSPHRASE")), nil
+//		},
+//	})
+//
+// To keep the private key out of the process, for instance in an HSM, a cloud KMS or an OS
+// keychain, set [Options.Signer] instead. [NewCryptoSigner] adapts any [crypto.Signer], signing
+// with ES256, ES384, RS256 or EdDSA after its key, or with PS256 through
+// [NewCryptoSignerWithAlgorithm], and [RemoteSigner] delegates signing to a callback:
+//
+//	signer, err := wallet.NewCryptoSigner("my-key-id", kmsKey)
+//	if err != nil {
+//		// the key is neither EC P-256, EC P-384, RSA nor Ed25519
+//	}
+//	client := wallet.New(&wallet.Options{Signer: signer})
+//
+// # Generating Keys
+//
+// API keys are created in Settings > API Keys of the Halogen Wallet from a Certificate Signing
+// Request (CSR). [GenerateKeyPair] generates an EC P-256 or RSA-4096 private key and its CSR,
+// optionally encrypting the private key with a passphrase, and [KeyPair.WriteFiles] writes the
+// private key with 0600 permissions:
+//
+//	pair, err := wallet.GenerateKeyPair(&wallet.GenerateKeyPairInput{
+//		Subject: pkix.Name{CommonName: "example.com", Organization: []string{"Organization"}},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	err = pair.WriteFiles(".key/ec_private_key.pem", ".key/ec_csr.pem")
+//
+// [CheckCSR] checks any CSR against the constraints of the server, returning an [Error] with
+// the code it would be rejected with, such as [ErrInvalidCSREllipticCurve].
+//
+// # Concurrency
+//
+// A [Client] is safe for concurrent use and is meant to be shared. [New] copies its options,
+// so changing them afterwards has no effect. Credentials may be replaced at any time with
+// [Client.RotateCredentials], which validates the new key and swaps it atomically: requests
+// already signed complete with the previous key, and later requests use the new one.
+//
+// # Key Rotation
+//
+// To rotate keys without downtime, hold them in a [KeyRing] with their activation and expiry
+// times, and set it as [Options.Signer]. Requests are signed with the preferred key while it is
+// valid, and a request rejected with [ErrExpiredApiKey], [ErrInvalidAuthSignature] or
+// [ErrInvalidPublicKey] is sent again with the next valid key. [KeyRingOptions.OnEvent] is told
+// when a key is about to expire, expires, is rejected or is replaced:
+//
+//	ring := wallet.NewKeyRing(&wallet.KeyRingOptions{
+//		ExpiryWarning: 30 * 24 * time.Hour,
+//		OnEvent: func(e wallet.KeyEvent) {
+//			slog.Warn("wallet key", "kind", e.Kind, "keyId", e.KeyID, "notAfter", e.NotAfter)
+//		},
+//	})
+//	if err := ring.Add(wallet.Key{ID: "key-2025", PrivateKeyPEM: pem2025, NotAfter: expiry2025}); err != nil {
+//		return err
+//	}
+//	if err := ring.Add(wallet.Key{ID: "key-2026", PrivateKeyPEM: pem2026}); err != nil {
+//		return err
+//	}
+//	client := wallet.New(&wallet.Options{Signer: ring})
+//
+// # Environments
+//
+// By default the client calls the production API. Set [Options.Environment] to [EnvironmentSandbox] to
+// call the sandbox instead, or set [Options.BaseURL] to call any other server, such as a staging gateway,
+// an egress proxy or a local test server:
+//
+//	client := wallet.New(&wallet.Options{BaseURL: "https://proxy.internal/wallet"})
+//
+// # Read-only and Dry-run Modes
+//
+// Set [Options.ReadOnly] for clients that must never move money, such as analytics jobs: every command
+// is refused locally with an error wrapping [ErrReadOnly], and only queries are sent. Set [Options.DryRun]
+// to rehearse commands: they are validated and signed, the redacted payload and the token claims are
+// logged, along with the exact payload at debug level, and a synthetic output is returned without sending
+// them. Both modes are fixed when the client is created.
+//
+// # Interceptors
+//
+// Every query and command travels through a chain of [Interceptor] functions before it is signed
+// and sent. Set [Options.Interceptors] to add logging, metrics, auditing, caching or request mutation:
+//
+//	client := wallet.New(&wallet.Options{
+//		Interceptors: []wallet.Interceptor{
+//			func(ctx context.Context, call *wallet.Call, next wallet.Next) error {
+//				start := time.Now()
+//				err := next(ctx, call)
+//				log.Printf("%s %s took %s (attempts=%d)", call.Kind, call.Name, time.Since(start), call.Attempt)
+//				return err
+//			},
+//		},
+//	})
+//
+// # Logging
+//
+// Set [Options.Logger] to log every call with [log/slog], at debug level when it succeeds and at warn
+// level when it fails. Records carry the API name, the HTTP status, the duration, the number of attempts
+// and the key ID. Bodies are only logged when [Options.LogBodyLimit] is set, capped to that size, with the
+// token and the personal data of the client redacted:
+//
+//	client := wallet.New(&wallet.Options{
+//		Logger:       slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})),
+//		LogBodyLimit: 4 << 10,
+//	})
+//
+// OpenTelemetry traces and metrics are provided by the separate module
+// github.com/halogencapital/wallet-go/otelwallet, as an [Interceptor], so that this package does not
+// depend on OpenTelemetry.
 //
 // # Rate Limiting
 //
@@ -33,12 +157,148 @@
 // This means you can make up to 10 requests immediately (burst), but sustained traffic is limited to 10 requests per second.
 //
 // If you exceed the rate limit, the server will respond with an HTTP 429 (Too Many Requests) error.
-// The client automatically retries requests when receiving a 429 response. This ensures that rate limit
-// errors are handled transparently without manual intervention.
+// The client automatically retries requests when receiving a 429 response, waiting for the duration in
+// the Retry-After header. This ensures that rate limit errors are handled transparently without manual intervention.
+//
+// To stay within the limit in the first place, set [Options.RateLimiter] to a [RateLimiter]. It holds
+// requests on the client side until their key has a token available, and pauses a key for the duration
+// in Retry-After when the server answers with a 429:
+//
+//	limiter := wallet.NewRateLimiter(wallet.DefaultRateLimit, wallet.DefaultRateLimitBurst)
+//	client := wallet.New(&wallet.Options{RateLimiter: limiter})
+//
+// # Retries
+//
+// Retries are bounded and governed by [Options.RetryPolicy]:
+//
+//   - HTTP 429 is retried, up to [RetryPolicy.MaxRateLimitedAttempts].
+//   - HTTP status codes >= 500 are retried, up to [RetryPolicy.MaxServerErrorAttempts].
+//   - Transient network errors, such as a connection reset or a timeout, are retried,
+//     up to [RetryPolicy.MaxNetworkErrorAttempts].
+//
+// Commands are only retried on server and network errors when they carry an idempotency key, set with
+// [WithIdempotencyKey] and sent in their signed body. The server executes a command at most once per key,
+// and [CreateInvestmentRequestOutput.Existing] reports when it returned a request created earlier. A
+// command without a key is sent once, since sending it again may duplicate a money movement. The key is
+// bound to the first command sent with the returned context, and a different command sent with it fails
+// with [ErrIdempotencyKeyReused], so derive a new context for each command.
+//
+// Waits grow exponentially with jitter, the total time spent is capped by [RetryPolicy.MaxElapsedTime],
+// and a wait ends early with the context's error when the context passed to the call is done.
+//
+// # Pagination
+//
+// [Client.AllClientAccountRequests] iterates over all the requests of an account, fetching them page
+// by page and deduplicating requests seen twice when new ones are created during the scan:
+//
+//	for r, err := range client.AllClientAccountRequests(ctx, &wallet.ListClientAccountRequestsInput{AccountID: accountID}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(r.ID, r.Status)
+//	}
+//
+// [Client.CollectClientAccountRequests] returns them as a slice.
+//
+// # Pre-flight Validation
+//
+// [Client.ValidateInvestment], [Client.ValidateRedemption] and [Client.ValidateSwitch] check a command
+// before it is sent: whether the account may invest, redeem or switch, whether the fund is in service,
+// the minimum amounts and units, the balance held, the required consents and the suitability assessment.
+// They return a [ValidationReport] listing every broken rule with the error code the command would fail
+// with, rather than only the first one:
+//
+//	report, err := client.ValidateRedemption(ctx, input)
+//	if err != nil {
+//		return err
+//	}
+//	for _, v := range report.Violations {
+//		log.Printf("%s: %s (%s)", v.Field, v.Message, v.Code)
+//	}
+//
+// # Investment Flow
+//
+// [InvestmentFlow] runs the chain of calls it takes to invest: it checks the fund class is open for
+// subscription, lists the required consents, previews the fee, applies the voucher, creates the request
+// with the quoted consents and voucher, and downloads its confirmation. The quote is approved before the
+// request is created, which fails with [ErrFeeChanged] when the fee moved in between:
+//
+//	flow := client.NewInvestmentFlow(accountID, fundID, 1, amount)
+//	result, err := flow.Run(ctx, func(quote *wallet.InvestmentQuote) error {
+//		// show the consents and the fee to the investor, then
+//		flow.AcceptConsents("IM", "highRisk")
+//		return nil
+//	})
+//
+// # Waiting for Requests
+//
+// Investments, redemptions and switches are processed asynchronously. [Client.WaitForRequest] polls a
+// request with backoff until it is completed, cancelled or rejected, and [Client.WatchRequests] sends an
+// event on a channel whenever the status of a request changes or, for joint accounts, a signatory signs:
+//
+//	for event := range client.WatchRequests(ctx, accountID, requestIDs, nil) {
+//		if event.Signatories != nil {
+//			log.Printf("%s: %d of %d signatures", event.Request.ID, event.Signatories.Signed, event.Signatories.Required)
+//		}
+//		log.Printf("%s: %s -> %s", event.Request.ID, event.PreviousStatus, event.Request.Status)
+//	}
+//
+// # Amounts
+//
+// Amounts, units, prices and percentages are of type [Decimal], an exact decimal number that keeps
+// the digits sent by the server, so that values reconcile with fund statements to the last digit.
+// Round computed values with [Decimal.Round], for instance to [CurrencyScale] for amounts and
+// [UnitScale] for units:
+//
+//	units := amount.Div(price.NetAssetValuePerUnit, wallet.UnitScale, wallet.RoundDown)
+//
+// Code using float64 amounts may convert with [NewDecimalFromFloat] and [Decimal.Float64].
+//
+// # Enumerations
+//
+// Timeframes, intervals, document formats, request types and statuses, fund statuses, risk
+// ratings and investor categories have their own types, such as [Timeframe] and [RequestStatus],
+// with a constant for each value. Inputs holding an undeclared value are rejected with
+// [ErrInvalidParameter] before being sent. Outputs keep values added to the API after this
+// version of the SDK, for which IsKnown reports false:
+//
+//	if !fund.Status.IsKnown() {
+//		log.Printf("fund %s has status %s", fund.ID, fund.Status)
+//	}
+//
+// # Dates
+//
+// Calendar days, such as the dates of a statement, are of type [Date] and instants, such as
+// the creation time of a request, are of type [Timestamp]. Business days are in Malaysia time,
+// [MalaysiaTime], whatever the time zone of the host: use [DateOf] for the day a timestamp
+// falls on, and [Today] for the current day.
+//
+//	input := &wallet.GetClientAccountStatementInput{
+//		AccountID: accountID,
+//		FromDate:  wallet.NewDate(2025, time.January, 1),
+//		ToDate:    wallet.Today(),
+//		Format:    wallet.DocumentFormatPDF,
+//	}
+//
+// A date range whose first day is after its last day is rejected with [ErrInvalidDateRange]
+// before being sent.
+//
+// # Errors
+//
+// Errors returned by the server are of type [Error], carrying the code, the request ID, the API name
+// and the fields at fault. Use [errors.Is] with a category such as [ErrBusinessRule], or with an Error
+// holding a code, and the predicates [IsAuth], [IsValidation], [IsBusinessRule], [IsRateLimited] and
+// [IsRetryable]:
+//
+//	_, err := client.CreateInvestmentRequest(ctx, input)
+//	switch {
+//	case errors.Is(err, wallet.Error{Code: wallet.ErrInsufficientBalance}):
+//		// top up the account
+//	case wallet.IsRetryable(err):
+//		// try again later
+//	}
 //
-// Note: The retry configuration in [Client.Options] (MaxReadRetry and RetryInterval) only applies to
-// read operations when the server responds with HTTP status codes >= 500. Rate limit retries (429 errors)
-// are handled separately and automatically.
+// Failures to reach the server wrap [ErrTransport], and failures to decode its response wrap [ErrDecode].
 //
 // # Example
 //
@@ -94,10 +354,10 @@
 //		if err != nil {
 //			log.Fatal(err)
 //		}
-//		log.Printf("Fund NAV: %f %s\n", price.NetAssetValuePerUnit, price.Asset)
+//		log.Printf("Fund NAV: %s %s\n", price.NetAssetValuePerUnit, price.Asset)
 //
 //		// Create an investment request
-//		investmentAmount := 10000.0
+//		investmentAmount := wallet.NewDecimalFromInt(10000)
 //		investReq, err := client.CreateInvestmentRequest(ctx, &wallet.CreateInvestmentRequestInput{
 //			AccountID:         accountID,
 //			FundID:            fundID,
@@ -0,0 +1,56 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// DryRunIDPrefix starts the identifiers returned by commands of a client created with
+// [Options.DryRun], such as [CreateInvestmentRequestOutput.RequestID].
+const DryRunIDPrefix = "dry-run-"
+
+// ReadOnly reports whether the client refuses commands, see [Options.ReadOnly].
+func (c *Client) ReadOnly() bool {
+	return c.readOnly
+}
+
+// DryRun reports whether the client signs commands without sending them, see [Options.DryRun].
+func (c *Client) DryRun() bool {
+	return c.dryRun
+}
+
+// fakeRoundTrip answers a signed command of a dry-run client without sending it: it logs the
+// redacted body and the claims of the token, and the exact signed body at debug level, then fills
+// call.Output with identifiers derived from the token nonce.
+func (c *Client) fakeRoundTrip(ctx context.Context, call *Call, t *token, body []byte) error {
+	c.logger.LogAttrs(ctx, slog.LevelInfo, "wallet: dry run, command is not sent",
+		slog.String("api", call.Name),
+		slog.String("body", redactBody(body, false)),
+		slog.Any("header", t.Header),
+		slog.Any("claims", t.Payload),
+	)
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "wallet: dry run, exact signed body",
+		slog.String("api", call.Name),
+		slog.String("body", string(body)),
+	)
+
+	id := DryRunIDPrefix + t.Payload.Nonce[:16]
+	switch output := call.Output.(type) {
+	case **CreateInvestmentRequestOutput:
+		*output = &CreateInvestmentRequestOutput{RequestID: id}
+	case **CreateRedemptionRequestOutput:
+		*output = &CreateRedemptionRequestOutput{RequestID: id}
+	case **CreateSwitchRequestOutput:
+		*output = &CreateSwitchRequestOutput{RequestID: id}
+	case **CreateSuitabilityAssessmentOutput:
+		*output = &CreateSuitabilityAssessmentOutput{SuitabilityAssessmentID: id}
+	default:
+		// the other commands return no identifier, their output is left empty.
+		if err := json.Unmarshal([]byte("{}"), call.Output); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrDecode, call.Name, err)
+		}
+	}
+	return nil
+}
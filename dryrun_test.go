@@ -0,0 +1,107 @@
+package wallet_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func TestReadOnly(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	o := &wallet.Options{ReadOnly: true}
+	c := srv.NewClient(o)
+	o.ReadOnly = false
+	ctx := context.Background()
+
+	if !c.ReadOnly() {
+		t.Fatal("expected the client to stay read-only")
+	}
+	_, err := c.UpdateAccountName(ctx, &wallet.UpdateAccountNameInput{AccountID: wallettest.SingleAccountID, AccountName: "Savings"})
+	if !errors.Is(err, wallet.ErrReadOnly) {
+		t.Fatalf("got %v, want %v", err, wallet.ErrReadOnly)
+	}
+	if _, err := c.ListClientAccounts(ctx, &wallet.ListClientAccountsInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if calls := srv.Calls(); len(calls) != 1 || calls[0].Kind != "query" {
+		t.Fatalf("expected only the query to be sent, got %+v", calls)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	var logs bytes.Buffer
+	var kinds []wallet.CallKind
+	c := srv.NewClient(&wallet.Options{
+		DryRun: true,
+		Logger: slog.New(slog.NewJSONHandler(&logs, nil)),
+		Interceptors: []wallet.Interceptor{
+			func(ctx context.Context, call *wallet.Call, next wallet.Next) error {
+				kinds = append(kinds, call.Kind)
+				return next(ctx, call)
+			},
+		},
+	})
+	output, err := c.CreateInvestmentRequest(context.Background(), &wallet.CreateInvestmentRequestInput{
+		AccountID:         wallettest.SingleAccountID,
+		FundID:            wallettest.BitcoinFundID,
+		FundClassSequence: 1,
+		Amount:            wallet.MustParseDecimal("1000"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output.RequestID, wallet.DryRunIDPrefix) {
+		t.Fatalf("got request ID %q, want a synthetic one", output.RequestID)
+	}
+	if len(srv.Calls()) != 0 || len(kinds) != 1 {
+		t.Fatalf("expected the command to go through the interceptors without being sent")
+	}
+	for _, want := range []string{`"api":"create_investment_request"`, `\"amount\":1000`, `"alg":"ES256"`, `"bodyHash":"`, `"uri":"/command"`} {
+		if !strings.Contains(logs.String(), want) {
+			t.Fatalf("expected %s to be logged, got %s", want, logs.String())
+		}
+	}
+
+	// invalid commands are refused before being signed.
+	logs.Reset()
+	_, err = c.CreateRedemptionRequest(context.Background(), &wallet.CreateRedemptionRequestInput{AccountID: wallettest.SingleAccountID, FundID: wallettest.BitcoinFundID})
+	if !errors.Is(err, wallet.Error{Code: wallet.ErrMissingParameter}) || logs.Len() != 0 {
+		t.Fatalf("got %v and logs %s, want %s without logs", err, logs.String(), wallet.ErrMissingParameter)
+	}
+	if werr := err.(wallet.Error); len(werr.Details) != 2 || werr.Details[0].Field != "fundClassSequence" || werr.Details[1].Field != "requestedAmount" {
+		t.Fatalf("unexpected details %+v", werr.Details)
+	}
+
+	// the payload is redacted at info level, and commands without identifiers return an empty output.
+	logs.Reset()
+	updated, err := c.UpdateAccountName(context.Background(), &wallet.UpdateAccountNameInput{AccountID: wallettest.SingleAccountID, AccountName: "Savings"})
+	if err != nil || updated == nil {
+		t.Fatalf("expected an empty output, got %v", err)
+	}
+	if strings.Contains(logs.String(), "Savings") {
+		t.Fatalf("expected the account name to be redacted, got %s", logs.String())
+	}
+
+	// the exact payload is logged at debug level.
+	logs.Reset()
+	c = srv.NewClient(&wallet.Options{DryRun: true, Logger: slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))})
+	if _, err := c.UpdateAccountName(context.Background(), &wallet.UpdateAccountNameInput{AccountID: wallettest.SingleAccountID, AccountName: "Savings"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := `\"accountName\":\"Savings\"`; !strings.Contains(logs.String(), want) {
+		t.Fatalf("expected %s to be logged, got %s", want, logs.String())
+	}
+
+	if _, err := c.ListBanks(context.Background(), &wallet.ListBanksInput{}); err != nil || len(srv.Calls()) != 1 {
+		t.Fatalf("expected queries to be sent, got %v", err)
+	}
+}
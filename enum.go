@@ -0,0 +1,318 @@
+package wallet
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Enumerated values of the API.
+//
+// Each type declares the values known to this version of the SDK. Decoding is tolerant: a value
+// added to the API later is kept as is, and reported by IsKnown as unknown, so that responses
+// keep decoding. Inputs are validated before being sent, see [Error].
+
+// Timeframe specifies the period covered by a performance query.
+type Timeframe string
+
+const (
+	Timeframe1M  Timeframe = "1m"
+	Timeframe3M  Timeframe = "3m"
+	Timeframe6M  Timeframe = "6m"
+	TimeframeYTD Timeframe = "ytd"
+	Timeframe1Y  Timeframe = "1y"
+	Timeframe3Y  Timeframe = "3y"
+	TimeframeAll Timeframe = "all"
+)
+
+var timeframes = []Timeframe{Timeframe1M, Timeframe3M, Timeframe6M, TimeframeYTD, Timeframe1Y, Timeframe3Y, TimeframeAll}
+
+// String returns the value as sent to the API.
+func (v Timeframe) String() string {
+	return string(v)
+}
+
+// IsKnown reports whether v is one of the declared values.
+func (v Timeframe) IsKnown() bool {
+	return slices.Contains(timeframes, v)
+}
+
+// MarshalText implements [encoding.TextMarshaler]. Unknown values are marshaled as is.
+func (v Timeframe) MarshalText() ([]byte, error) {
+	return []byte(v), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. Declared values are matched regardless
+// of case, unknown values are kept as is.
+func (v *Timeframe) UnmarshalText(b []byte) error {
+	*v = parseEnum(b, timeframes)
+	return nil
+}
+
+// Interval specifies the time between two points of a performance query.
+type Interval string
+
+const (
+	IntervalDaily   Interval = "daily"
+	IntervalWeekly  Interval = "weekly"
+	IntervalMonthly Interval = "monthly"
+)
+
+var intervals = []Interval{IntervalDaily, IntervalWeekly, IntervalMonthly}
+
+// String returns the value as sent to the API.
+func (v Interval) String() string {
+	return string(v)
+}
+
+// IsKnown reports whether v is one of the declared values.
+func (v Interval) IsKnown() bool {
+	return slices.Contains(intervals, v)
+}
+
+// MarshalText implements [encoding.TextMarshaler]. Unknown values are marshaled as is.
+func (v Interval) MarshalText() ([]byte, error) {
+	return []byte(v), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. Declared values are matched regardless
+// of case, unknown values are kept as is.
+func (v *Interval) UnmarshalText(b []byte) error {
+	*v = parseEnum(b, intervals)
+	return nil
+}
+
+// DocumentFormat specifies the format of statements and confirmations.
+type DocumentFormat string
+
+const (
+	DocumentFormatPDF  DocumentFormat = "pdf"
+	DocumentFormatHTML DocumentFormat = "html"
+)
+
+var documentFormats = []DocumentFormat{DocumentFormatPDF, DocumentFormatHTML}
+
+// String returns the value as sent to the API.
+func (v DocumentFormat) String() string {
+	return string(v)
+}
+
+// IsKnown reports whether v is one of the declared values.
+func (v DocumentFormat) IsKnown() bool {
+	return slices.Contains(documentFormats, v)
+}
+
+// MarshalText implements [encoding.TextMarshaler]. Unknown values are marshaled as is.
+func (v DocumentFormat) MarshalText() ([]byte, error) {
+	return []byte(v), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. Declared values are matched regardless
+// of case, unknown values are kept as is.
+func (v *DocumentFormat) UnmarshalText(b []byte) error {
+	*v = parseEnum(b, documentFormats)
+	return nil
+}
+
+// RequestType specifies the type of a [ClientAccountRequest]. Fund management accounts have
+// investment, redemption and switch requests, DIM accounts have deposit and withdrawal requests.
+type RequestType string
+
+const (
+	RequestTypeInvestment RequestType = "investment"
+	RequestTypeRedemption RequestType = "redemption"
+	RequestTypeSwitchOut  RequestType = "switch out"
+	RequestTypeSwitchIn   RequestType = "switch in"
+	RequestTypeDeposit    RequestType = "deposit"
+	RequestTypeWithdrawal RequestType = "withdrawal"
+)
+
+var requestTypes = []RequestType{RequestTypeInvestment, RequestTypeRedemption, RequestTypeSwitchOut, RequestTypeSwitchIn, RequestTypeDeposit, RequestTypeWithdrawal}
+
+// String returns the value as sent to the API.
+func (v RequestType) String() string {
+	return string(v)
+}
+
+// IsKnown reports whether v is one of the declared values.
+func (v RequestType) IsKnown() bool {
+	return slices.Contains(requestTypes, v)
+}
+
+// MarshalText implements [encoding.TextMarshaler]. Unknown values are marshaled as is.
+func (v RequestType) MarshalText() ([]byte, error) {
+	return []byte(v), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. Declared values are matched regardless
+// of case, unknown values are kept as is.
+func (v *RequestType) UnmarshalText(b []byte) error {
+	*v = parseEnum(b, requestTypes)
+	return nil
+}
+
+// RequestStatus specifies the status of a [ClientAccountRequest].
+type RequestStatus string
+
+const (
+	RequestStatusPending   RequestStatus = "pending"
+	RequestStatusCompleted RequestStatus = "completed"
+	RequestStatusCancelled RequestStatus = "cancelled"
+	RequestStatusRejected  RequestStatus = "rejected"
+)
+
+var requestStatuses = []RequestStatus{RequestStatusPending, RequestStatusCompleted, RequestStatusCancelled, RequestStatusRejected}
+
+// String returns the value as sent to the API.
+func (v RequestStatus) String() string {
+	return string(v)
+}
+
+// IsKnown reports whether v is one of the declared values.
+func (v RequestStatus) IsKnown() bool {
+	return slices.Contains(requestStatuses, v)
+}
+
+// MarshalText implements [encoding.TextMarshaler]. Unknown values are marshaled as is.
+func (v RequestStatus) MarshalText() ([]byte, error) {
+	return []byte(v), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. Declared values are matched regardless
+// of case, unknown values are kept as is.
+func (v *RequestStatus) UnmarshalText(b []byte) error {
+	*v = parseEnum(b, requestStatuses)
+	return nil
+}
+
+// IsTerminal reports whether v is final: completed, cancelled or rejected. Unknown statuses
+// are not terminal.
+func (v RequestStatus) IsTerminal() bool {
+	return v == RequestStatusCompleted || v == RequestStatusCancelled || v == RequestStatusRejected
+}
+
+// FundStatus specifies the status of a [Fund].
+type FundStatus string
+
+const (
+	FundStatusPending  FundStatus = "pending"
+	FundStatusActive   FundStatus = "active"
+	FundStatusArchived FundStatus = "archived"
+)
+
+var fundStatuses = []FundStatus{FundStatusPending, FundStatusActive, FundStatusArchived}
+
+// String returns the value as sent to the API.
+func (v FundStatus) String() string {
+	return string(v)
+}
+
+// IsKnown reports whether v is one of the declared values.
+func (v FundStatus) IsKnown() bool {
+	return slices.Contains(fundStatuses, v)
+}
+
+// MarshalText implements [encoding.TextMarshaler]. Unknown values are marshaled as is.
+func (v FundStatus) MarshalText() ([]byte, error) {
+	return []byte(v), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. Declared values are matched regardless
+// of case, unknown values are kept as is.
+func (v *FundStatus) UnmarshalText(b []byte) error {
+	*v = parseEnum(b, fundStatuses)
+	return nil
+}
+
+// RiskRating specifies the amount of risk of a [Fund].
+type RiskRating string
+
+const (
+	RiskRatingLow      RiskRating = "low"
+	RiskRatingModerate RiskRating = "moderate"
+	RiskRatingHigh     RiskRating = "high"
+)
+
+var riskRatings = []RiskRating{RiskRatingLow, RiskRatingModerate, RiskRatingHigh}
+
+// String returns the value as sent to the API.
+func (v RiskRating) String() string {
+	return string(v)
+}
+
+// IsKnown reports whether v is one of the declared values.
+func (v RiskRating) IsKnown() bool {
+	return slices.Contains(riskRatings, v)
+}
+
+// MarshalText implements [encoding.TextMarshaler]. Unknown values are marshaled as is.
+func (v RiskRating) MarshalText() ([]byte, error) {
+	return []byte(v), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. Declared values are matched regardless
+// of case, unknown values are kept as is.
+func (v *RiskRating) UnmarshalText(b []byte) error {
+	*v = parseEnum(b, riskRatings)
+	return nil
+}
+
+// InvestorCategory specifies the investor category of a client, see [GetClientProfileOutput].
+type InvestorCategory string
+
+const (
+	InvestorCategoryAccredited        InvestorCategory = "accreditedInvestor"
+	InvestorCategoryHighNetworth      InvestorCategory = "highNetworthInvestor"
+	InvestorCategorySophisticated250k InvestorCategory = "sophisticatedInvestor250k"
+	InvestorCategoryRetail            InvestorCategory = "retailInvestor"
+)
+
+var investorCategories = []InvestorCategory{InvestorCategoryAccredited, InvestorCategoryHighNetworth, InvestorCategorySophisticated250k, InvestorCategoryRetail}
+
+// String returns the value as sent to the API.
+func (v InvestorCategory) String() string {
+	return string(v)
+}
+
+// IsKnown reports whether v is one of the declared values.
+func (v InvestorCategory) IsKnown() bool {
+	return slices.Contains(investorCategories, v)
+}
+
+// MarshalText implements [encoding.TextMarshaler]. Unknown values are marshaled as is.
+func (v InvestorCategory) MarshalText() ([]byte, error) {
+	return []byte(v), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. Declared values are matched regardless
+// of case, unknown values are kept as is.
+func (v *InvestorCategory) UnmarshalText(b []byte) error {
+	*v = parseEnum(b, investorCategories)
+	return nil
+}
+
+// parseEnum returns the declared value matching b regardless of case, otherwise b as is.
+func parseEnum[T ~string](b []byte, known []T) T {
+	for _, v := range known {
+		if strings.EqualFold(string(v), string(b)) {
+			return v
+		}
+	}
+	return T(b)
+}
+
+// checkEnum appends an [ErrInvalidParameter] detail to details when v is set and unknown.
+func checkEnum[T ~string](details []ErrorDetail, field string, v T, known []T) []ErrorDetail {
+	if v == "" || slices.Contains(known, v) {
+		return details
+	}
+	values := make([]string, len(known))
+	for i, k := range known {
+		values[i] = string(k)
+	}
+	return append(details, ErrorDetail{
+		Field:   field,
+		Code:    ErrInvalidParameter,
+		Message: fmt.Sprintf("%s %q is invalid. Valid %s would be one of %s", field, v, field, strings.Join(values, ", ")),
+	})
+}
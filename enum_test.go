@@ -0,0 +1,93 @@
+package wallet_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func TestEnumDecoding(t *testing.T) {
+	var fund wallet.Fund
+	if err := json.Unmarshal([]byte(`{"status":"suspended","riskRating":"High"}`), &fund); err != nil {
+		t.Fatal(err)
+	}
+	if fund.Status != "suspended" || fund.Status.IsKnown() {
+		t.Fatalf("got status %q, want the unknown value kept", fund.Status)
+	}
+	if fund.RiskRating != wallet.RiskRatingHigh || !fund.RiskRating.IsKnown() {
+		t.Fatalf("got risk rating %q, want %q", fund.RiskRating, wallet.RiskRatingHigh)
+	}
+	b, err := json.Marshal(wallet.ListClientAccountRequestsInput{
+		Types:    []wallet.RequestType{wallet.RequestTypeSwitchOut},
+		Statuses: []wallet.RequestStatus{wallet.RequestStatusPending},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"types":["switch out"],"statuses":["pending"]}`; string(b) != want {
+		t.Fatalf("got %s, want %s", b, want)
+	}
+}
+
+func TestEnumValidation(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	ctx := context.Background()
+
+	_, err := c.ListClientAccountPerformance(ctx, &wallet.ListClientAccountPerformanceInput{
+		Timeframe: "1w",
+		Interval:  "hourly",
+	})
+	var werr wallet.Error
+	if !errors.As(err, &werr) || !errors.Is(err, wallet.Error{Code: wallet.ErrInvalidParameter}) || !wallet.IsValidation(err) {
+		t.Fatalf("got %v, want %s", err, wallet.ErrInvalidParameter)
+	}
+	if len(werr.Details) != 2 || werr.Details[0].Field != "timeframe" || werr.Details[1].Field != "interval" {
+		t.Fatalf("unexpected details %+v", werr.Details)
+	}
+	if werr.APIName != "list_client_account_performance" {
+		t.Fatalf("got api %q", werr.APIName)
+	}
+	if calls := srv.Calls(); len(calls) != 0 {
+		t.Fatalf("expected the invalid input not to be sent, got %d calls", len(calls))
+	}
+
+	_, err = c.ListClientAccountRequests(ctx, &wallet.ListClientAccountRequestsInput{
+		AccountID: wallettest.SingleAccountID,
+		Statuses:  []wallet.RequestStatus{"done"},
+	})
+	if !errors.Is(err, wallet.ErrValidation) {
+		t.Fatalf("got %v, want a validation error", err)
+	}
+
+	output, err := c.ListClientAccountRequests(ctx, &wallet.ListClientAccountRequestsInput{
+		AccountID: wallettest.SingleAccountID,
+		Statuses:  []wallet.RequestStatus{wallet.RequestStatusCompleted},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range output.Requests {
+		if r.Status != wallet.RequestStatusCompleted {
+			t.Fatalf("got request %s with status %q", r.ID, r.Status)
+		}
+	}
+	statement, err := c.GetClientAccountStatement(ctx, &wallet.GetClientAccountStatementInput{
+		AccountID: wallettest.SingleAccountID,
+		FromDate:  wallet.NewDate(2025, time.January, 1),
+		ToDate:    wallet.NewDate(2025, time.January, 31),
+		Format:    wallet.DocumentFormatHTML,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statement.Format != wallet.DocumentFormatHTML {
+		t.Fatalf("got format %q", statement.Format)
+	}
+}
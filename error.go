@@ -1,5 +1,11 @@
 package wallet
 
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
 const (
 	// Error codes returned by the Wallet SDK
 	//
@@ -143,12 +149,161 @@ const (
 	ErrServiceUnavailable string = "ErrServiceUnavailable"
 )
 
+// Categories of errors, usable with [errors.Is]. An [Error] matches the category of its Code,
+// following the groupings of the codes above, or of its StatusCode when the code is unknown:
+//
+//	if errors.Is(err, wallet.ErrBusinessRule) {
+//		// tell the user why the request was refused
+//	}
+//
+// A specific code is matched with an Error holding only that code:
+//
+//	if errors.Is(err, wallet.Error{Code: wallet.ErrInsufficientBalance}) {
+//		// top up the account
+//	}
+var (
+	// ErrAuth matches authentication and authorization errors.
+	ErrAuth = errors.New("wallet: authentication error")
+
+	// ErrValidation matches request validation and CSR errors.
+	ErrValidation = errors.New("wallet: validation error")
+
+	// ErrResource matches resource and routing errors.
+	ErrResource = errors.New("wallet: resource error")
+
+	// ErrBusinessRule matches errors returned when a request breaks a business or domain rule.
+	ErrBusinessRule = errors.New("wallet: business rule error")
+
+	// ErrRateLimited matches errors returned when the rate limit is exceeded.
+	ErrRateLimited = errors.New("wallet: rate limited")
+
+	// ErrServer matches server and infrastructure errors.
+	ErrServer = errors.New("wallet: server error")
+
+	// ErrTransport wraps failures to send a request or to receive its response, such as
+	// connection errors and timeouts. The underlying error is available with [errors.As].
+	ErrTransport = errors.New("wallet: transport error")
+
+	// ErrDecode wraps failures to decode a successful response.
+	ErrDecode = errors.New("wallet: decode error")
+
+	// ErrReadOnly wraps the refusal of a command by a client created with [Options.ReadOnly].
+	// The command is neither signed nor sent.
+	ErrReadOnly = errors.New("wallet: read-only client")
+)
+
+// Error is an error returned by the server, or by the client for an input it rejects before
+// sending it, such as an unknown [Timeframe]. The latter has no StatusCode nor RequestID.
 type Error struct {
 	StatusCode int    `json:"statusCode"`
 	Code       string `json:"code"`
 	Message    string `json:"message"`
+
+	// RequestID identifies the request on the server. Share it when contacting support.
+	RequestID string `json:"requestId,omitempty"`
+
+	// Details lists the fields of the input the error relates to, if any.
+	Details []ErrorDetail `json:"details,omitempty"`
+
+	// RetryAfter is how long the server asked to wait before retrying, from the Retry-After header.
+	RetryAfter time.Duration `json:"-"`
+
+	// APIName is the name of the query or command that failed, for instance "list_client_accounts".
+	APIName string `json:"-"`
+}
+
+// ErrorDetail describes an error about one field of the input.
+type ErrorDetail struct {
+	Field   string `json:"field"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
 }
 
 func (e Error) Error() string {
+	if e.Message == "" {
+		return "wallet: " + e.APIName + ": " + http.StatusText(e.StatusCode)
+	}
 	return e.Message
 }
+
+// Is reports whether e matches target: an [Error] with the same Code, or the category of e,
+// see [ErrAuth].
+func (e Error) Is(target error) bool {
+	switch t := target.(type) {
+	case Error:
+		return t.Code != "" && t.Code == e.Code
+	case *Error:
+		return t != nil && t.Code != "" && t.Code == e.Code
+	}
+	return target != nil && target == e.category()
+}
+
+// category returns the category sentinel of e.
+func (e Error) category() error {
+	switch e.Code {
+	case ErrExpiredApiKey, ErrExpiredAuthToken, ErrInsufficientAccess, ErrInvalidAuthSignature,
+		ErrInvalidAuthToken, ErrInvalidPublicKey, ErrUnauthorizedIPAddress:
+		return ErrAuth
+	case ErrInvalidApiName, ErrInvalidBodyFormat, ErrInvalidDateRange, ErrInvalidHeader, ErrInvalidMethod,
+		ErrInvalidParameter, ErrInvalidPayload, ErrMissingHeader, ErrMissingParameter,
+		ErrInvalidCSR, ErrInvalidCSRFormat, ErrInvalidCSREllipticCurve, ErrInvalidCSRKeyLength,
+		ErrInvalidCSRKeyType, ErrInvalidCSRSignature:
+		return ErrValidation
+	case ErrAlreadyExists, ErrInvalidRoute, ErrMissingResource:
+		return ErrResource
+	case ErrActionNotAllowedForAccountType, ErrActionOutsideFundHours, ErrInsufficientBalance,
+		ErrInvalidAccountExperience, ErrInvalidRequestPolicy, ErrRequestCannotBeCancelled,
+		ErrSuitabilityAssessmentMissingForAccountCreation, ErrSuitabilityAssessmentRequired:
+		return ErrBusinessRule
+	case ErrRateLimitExceeded:
+		return ErrRateLimited
+	case ErrInternal, ErrServiceUnavailable:
+		return ErrServer
+	case ErrCancelledRequest:
+		return nil
+	}
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuth
+	case e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusConflict:
+		return ErrResource
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrServer
+	case e.StatusCode >= http.StatusBadRequest:
+		return ErrValidation
+	}
+	return nil
+}
+
+// IsAuth reports whether err is an authentication or authorization error, see [ErrAuth].
+func IsAuth(err error) bool {
+	return errors.Is(err, ErrAuth)
+}
+
+// IsValidation reports whether err is a request validation error, see [ErrValidation].
+func IsValidation(err error) bool {
+	return errors.Is(err, ErrValidation)
+}
+
+// IsBusinessRule reports whether err was returned because the request breaks a business or
+// domain rule, see [ErrBusinessRule].
+func IsBusinessRule(err error) bool {
+	return errors.Is(err, ErrBusinessRule)
+}
+
+// IsRateLimited reports whether err was returned because the rate limit is exceeded, see [ErrRateLimited].
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsRetryable reports whether the call that returned err may succeed when sent again: rate
+// limit and server errors, and transient transport errors. Commands are only safe to re-send
+// with the same idempotency key, see [WithIdempotencyKey].
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServer) {
+		return true
+	}
+	return errors.Is(err, ErrTransport) && isTransientNetworkError(err)
+}
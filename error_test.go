@@ -0,0 +1,94 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func TestErrorCategories(t *testing.T) {
+	tests := []struct {
+		err  wallet.Error
+		is   func(error) bool
+		want error
+	}{
+		{wallet.Error{Code: wallet.ErrExpiredApiKey}, wallet.IsAuth, wallet.ErrAuth},
+		{wallet.Error{Code: wallet.ErrInvalidCSRKeyType}, wallet.IsValidation, wallet.ErrValidation},
+		{wallet.Error{Code: wallet.ErrInsufficientBalance}, wallet.IsBusinessRule, wallet.ErrBusinessRule},
+		{wallet.Error{Code: wallet.ErrRateLimitExceeded}, wallet.IsRateLimited, wallet.ErrRateLimited},
+		{wallet.Error{Code: wallet.ErrServiceUnavailable}, wallet.IsRetryable, wallet.ErrServer},
+		{wallet.Error{StatusCode: http.StatusForbidden, Code: "ErrUnknown"}, wallet.IsAuth, wallet.ErrAuth},
+	}
+	for _, tt := range tests {
+		if !tt.is(tt.err) || !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: expected to match %v", tt.err.Code, tt.want)
+		}
+	}
+	if wallet.IsRetryable(wallet.Error{Code: wallet.ErrInsufficientBalance}) {
+		t.Error("expected a business rule error not to be retryable")
+	}
+	if errors.Is(wallet.Error{Code: wallet.ErrInsufficientBalance}, wallet.Error{Code: wallet.ErrInsufficientAccess}) {
+		t.Error("expected different codes not to match")
+	}
+}
+
+func TestErrorMetadata(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(&wallet.Options{RetryPolicy: &wallet.RetryPolicy{MaxRateLimitedAttempts: 1}})
+	ctx := context.Background()
+
+	_, err := c.ListClientAccountBalance(ctx, &wallet.ListClientAccountBalanceInput{})
+	var werr wallet.Error
+	if !errors.As(err, &werr) || !errors.Is(err, wallet.Error{Code: wallet.ErrMissingParameter}) {
+		t.Fatalf("got %v, want %s", err, wallet.ErrMissingParameter)
+	}
+	calls := srv.Calls()
+	if werr.APIName != "list_client_account_balance" || werr.RequestID != calls[len(calls)-1].RequestID {
+		t.Fatalf("got api=%q request=%q, want the failed call", werr.APIName, werr.RequestID)
+	}
+	if len(werr.Details) != 1 || werr.Details[0].Field != "accountId" {
+		t.Fatalf("unexpected details %+v", werr.Details)
+	}
+
+	srv.RateLimit("", 2*time.Second, 1)
+	_, err = c.ListClientAccounts(ctx, &wallet.ListClientAccountsInput{})
+	if !wallet.IsRateLimited(err) || !errors.As(err, &werr) || werr.RetryAfter != 2*time.Second {
+		t.Fatalf("got %v, want rate limited for 2s", err)
+	}
+}
+
+func TestErrorTransportAndDecode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"accounts":`))
+	}))
+	c := wallet.New(&wallet.Options{
+		Environment: wallet.EnvironmentCustom,
+		BaseURL:     srv.URL,
+		RetryPolicy: &wallet.RetryPolicy{MaxNetworkErrorAttempts: 1},
+	})
+	key := wallettest.NewServer(nil)
+	c.SetCredentials(key.GenerateKey())
+	key.Close()
+
+	_, err := c.ListClientAccounts(context.Background(), &wallet.ListClientAccountsInput{})
+	if !errors.Is(err, wallet.ErrDecode) || wallet.IsRetryable(err) {
+		t.Fatalf("got %v, want %v", err, wallet.ErrDecode)
+	}
+
+	srv.Close()
+	_, err = c.ListClientAccounts(context.Background(), &wallet.ListClientAccountsInput{})
+	if !errors.Is(err, wallet.ErrTransport) || !wallet.IsRetryable(err) {
+		t.Fatalf("got %v, want a retryable %v", err, wallet.ErrTransport)
+	}
+	var werr wallet.Error
+	if errors.As(err, &werr) {
+		t.Fatal("expected a transport error not to be an API error")
+	}
+}
@@ -0,0 +1,96 @@
+package wallet
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrIdempotencyKeyReused is returned, before anything is sent, by a command sent with a context
+// of [WithIdempotencyKey] already used for a different command: another API, or another payload.
+var ErrIdempotencyKeyReused = errors.New("wallet: idempotency key reused for a different command")
+
+type idempotencyKeyContextKey struct{}
+
+// idempotencyScope is the idempotency key of a context, and the command it was first used for.
+type idempotencyScope struct {
+	key string
+
+	mu sync.Mutex
+	// name and hash identify the first command sent with the key, hash being the SHA-256 of
+	// its JSON encoded input. name is empty until then.
+	name string
+	hash [sha256.Size]byte
+}
+
+// NewIdempotencyKey returns a random version 4 UUID suitable for [WithIdempotencyKey].
+func NewIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("wallet: NewIdempotencyKey: failed to read random bytes. err=%v", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// WithIdempotencyKey returns a copy of ctx that makes every command sent with it carry key.
+//
+// The server executes a command at most once per idempotency key, carried in its signed body.
+// Commands only carry a key set with WithIdempotencyKey, and only those are retried on server
+// and network errors: a command without a key is sent once, since sending it again may
+// duplicate a money movement. Set a key to retry a command safely, or to re-send a command from
+// a previous call whose outcome is unknown, for instance after a timeout.
+//
+// The key is bound to the first command sent with the returned ctx: sending it again, with the
+// same input, is answered with the outcome of the first one, while a different command, such as
+// a redemption after an investment, fails with [ErrIdempotencyKeyReused] without being sent.
+// Derive a new ctx for each logical command:
+//
+//	key, _ := wallet.NewIdempotencyKey()
+//	ctx := wallet.WithIdempotencyKey(ctx, key)
+//	output, err := client.CreateInvestmentRequest(ctx, input)
+//	if err != nil {
+//		// safe to call again with the same ctx and input, output.Existing reports whether
+//		// the investment had already been placed.
+//	}
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, &idempotencyScope{key: key})
+}
+
+// idempotencyKeyOf returns the key set on ctx with [WithIdempotencyKey], or an empty string.
+func idempotencyKeyOf(ctx context.Context) string {
+	if scope, ok := ctx.Value(idempotencyKeyContextKey{}).(*idempotencyScope); ok {
+		return scope.key
+	}
+	return ""
+}
+
+// idempotencyKey returns the key set on ctx with [WithIdempotencyKey] for the command name
+// with input, or an empty string without key. It binds the key to the command the first time,
+// and returns [ErrIdempotencyKeyReused] for any other command.
+func idempotencyKey(ctx context.Context, name string, input interface{}) (string, error) {
+	scope, ok := ctx.Value(idempotencyKeyContextKey{}).(*idempotencyScope)
+	if !ok || scope.key == "" {
+		return "", nil
+	}
+	b, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(b)
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+	if scope.name == "" {
+		scope.name, scope.hash = name, hash
+		return scope.key, nil
+	}
+	if scope.name != name || scope.hash != hash {
+		return "", fmt.Errorf("%w: key %q was used for %s, now %s", ErrIdempotencyKeyReused, scope.key, scope.name, name)
+	}
+	return scope.key, nil
+}
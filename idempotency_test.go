@@ -0,0 +1,124 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func investmentInput() *wallet.CreateInvestmentRequestInput {
+	return &wallet.CreateInvestmentRequestInput{
+		AccountID:         wallettest.SingleAccountID,
+		FundID:            wallettest.EthereumFundID,
+		FundClassSequence: 1,
+		Amount:            wallet.NewDecimalFromInt(2000),
+		Consents:          map[string]bool{"IM": true, "highRisk": true},
+	}
+}
+
+func countRequests(srv *wallettest.Server, accountID string) int {
+	n := 0
+	srv.State(func(seed *wallettest.Seed) {
+		n = len(seed.Requests[accountID])
+	})
+	return n
+}
+
+func TestIdempotentCommandRetry(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	before := countRequests(srv, wallettest.SingleAccountID)
+
+	// the investment is placed but its response is lost.
+	key, err := wallet.NewIdempotencyKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Inject(wallettest.Fault{Name: "create_investment_request", Code: wallet.ErrInternal, AfterHandling: true})
+	output, err := c.CreateInvestmentRequest(wallet.WithIdempotencyKey(context.Background(), key), investmentInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !output.Existing {
+		t.Fatal("expected the retried command to report the existing request")
+	}
+	if n := countRequests(srv, wallettest.SingleAccountID) - before; n != 1 {
+		t.Fatalf("got %d new requests, want 1", n)
+	}
+	calls := srv.Calls()
+	if len(calls) != 2 || calls[0].IdempotencyKey != key || calls[1].IdempotencyKey != key {
+		t.Fatalf("expected 2 calls with the same idempotency key, got %+v", calls)
+	}
+}
+
+func TestCommandWithoutIdempotencyKeyIsSentOnce(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+
+	srv.Inject(wallettest.Fault{Name: "create_investment_request", Code: wallet.ErrInternal, AfterHandling: true})
+	if _, err := c.CreateInvestmentRequest(context.Background(), investmentInput()); !errors.Is(err, wallet.Error{Code: wallet.ErrInternal}) {
+		t.Fatalf("got %v, want %s", err, wallet.ErrInternal)
+	}
+	calls := srv.Calls()
+	if len(calls) != 1 || calls[0].IdempotencyKey != "" {
+		t.Fatalf("expected a single call without idempotency key, got %+v", calls)
+	}
+}
+
+func TestWithIdempotencyKey(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+
+	key, err := wallet.NewIdempotencyKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := wallet.WithIdempotencyKey(context.Background(), key)
+	first, err := c.CreateInvestmentRequest(ctx, investmentInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.CreateInvestmentRequest(ctx, investmentInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Existing || !second.Existing || first.RequestID != second.RequestID {
+		t.Fatalf("got first=%+v second=%+v, want the same request replayed", first, second)
+	}
+	third, err := c.CreateInvestmentRequest(context.Background(), investmentInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Existing || third.RequestID == first.RequestID {
+		t.Fatalf("expected a new request without the idempotency key, got %+v", third)
+	}
+}
+
+func TestIdempotencyKeyReused(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+
+	ctx := wallet.WithIdempotencyKey(context.Background(), "investment-1")
+	if _, err := c.CreateInvestmentRequest(ctx, investmentInput()); err != nil {
+		t.Fatal(err)
+	}
+	other := investmentInput()
+	other.Amount = wallet.NewDecimalFromInt(3000)
+	if _, err := c.CreateInvestmentRequest(ctx, other); !errors.Is(err, wallet.ErrIdempotencyKeyReused) {
+		t.Fatalf("got %v, want %v", err, wallet.ErrIdempotencyKeyReused)
+	}
+	_, err := c.UpdateDisplayCurrency(ctx, &wallet.UpdateDisplayCurrencyInput{DisplayCurrency: "USD"})
+	if !errors.Is(err, wallet.ErrIdempotencyKeyReused) {
+		t.Fatalf("got %v, want %v", err, wallet.ErrIdempotencyKeyReused)
+	}
+	if n := len(srv.Calls()); n != 1 {
+		t.Fatalf("got %d calls, want only the first command sent", n)
+	}
+}
@@ -0,0 +1,88 @@
+package wallet
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// CallKind specifies whether a call is a query or a command.
+type CallKind string
+
+const (
+	// CallKindQuery is a read-only call sent to "/query".
+	CallKindQuery CallKind = "query"
+	// CallKindCommand is a state-changing call sent to "/command".
+	CallKindCommand CallKind = "command"
+)
+
+// Call describes a single API call travelling through the interceptor chain. Interceptors
+// may read and modify it before and after calling [Next].
+type Call struct {
+	// Kind specifies whether the call is a query or a command.
+	Kind CallKind
+
+	// Name specifies the API name, for instance "list_client_accounts".
+	Name string
+
+	// Input specifies the payload of the call, for instance *[ListClientAccountsInput].
+	Input interface{}
+
+	// Output is where the response is decoded into, for instance **[ListClientAccountsOutput].
+	// An interceptor that answers the call without calling [Next] must fill it in.
+	Output interface{}
+
+	// IdempotencyKey specifies the key a command is executed at most once for by the server, set
+	// with [WithIdempotencyKey]. It is carried in the signed request body and stays the same
+	// across retries.
+	//
+	// Empty for queries and for commands sent without a key. A command without a key is never
+	// retried on server or network errors.
+	IdempotencyKey string
+
+	// Header specifies extra headers sent with the request. Authorization and User-Agent
+	// are always set by the client.
+	Header http.Header
+
+	// KeyID is the key identifier the latest attempt was signed with.
+	KeyID string
+
+	// RateLimitWait is the total time spent waiting on [Options.RateLimiter] across attempts.
+	RateLimitWait time.Duration
+
+	// RateLimitedAttempts is the number of attempts the server answered with HTTP 429.
+	RateLimitedAttempts int
+
+	// SigningDuration is the total time spent loading the key and signing the token across attempts.
+	SigningDuration time.Duration
+
+	// Attempt is the number of times the request has been sent, starting at 1 on the first send.
+	Attempt int
+
+	// StatusCode is the HTTP status code of the latest response, zero until one is received.
+	StatusCode int
+
+	// ResponseHeader holds the headers of the latest response, nil until one is received.
+	ResponseHeader http.Header
+}
+
+// Next sends the call to the rest of the chain and eventually to the server.
+type Next func(ctx context.Context, call *Call) error
+
+// Interceptor wraps every call made by a [Client]. It may inspect or mutate the call, answer
+// it without calling next, or call next more than once.
+//
+// Interceptors are set with [Options.Interceptors]. The first interceptor is the outermost one.
+type Interceptor func(ctx context.Context, call *Call, next Next) error
+
+// chain returns a Next that runs interceptors in order before calling last.
+func chain(interceptors []Interceptor, last Next) Next {
+	next := last
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := interceptors[i], next
+		next = func(ctx context.Context, call *Call) error {
+			return interceptor(ctx, call, inner)
+		}
+	}
+	return next
+}
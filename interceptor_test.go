@@ -0,0 +1,88 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func TestInterceptors(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+
+	var trace []string
+	var attempts int
+	c := srv.NewClient(&wallet.Options{
+		Interceptors: []wallet.Interceptor{
+			func(ctx context.Context, call *wallet.Call, next wallet.Next) error {
+				trace = append(trace, "outer:"+call.Name)
+				call.Header.Set("X-Audit-ID", "audit-1")
+				err := next(ctx, call)
+				attempts = call.Attempt
+				return err
+			},
+			func(ctx context.Context, call *wallet.Call, next wallet.Next) error {
+				trace = append(trace, "inner:"+string(call.Kind))
+				return next(ctx, call)
+			},
+		},
+	})
+	srv.FailNext("list_banks", wallet.ErrInternal)
+	if _, err := c.ListBanks(context.Background(), &wallet.ListBanksInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(trace) != 2 || trace[0] != "outer:list_banks" || trace[1] != "inner:query" {
+		t.Fatalf("unexpected trace %v", trace)
+	}
+	if attempts != 2 {
+		t.Fatalf("got %d attempts, want 2", attempts)
+	}
+	for _, call := range srv.Calls() {
+		if call.Header.Get("X-Audit-ID") != "audit-1" {
+			t.Fatalf("header set by interceptor was not sent")
+		}
+	}
+}
+
+func TestInterceptorCommandsWithoutIdempotencyKeyAreNotRetried(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(&wallet.Options{
+		Interceptors: []wallet.Interceptor{
+			func(ctx context.Context, call *wallet.Call, next wallet.Next) error {
+				call.IdempotencyKey = ""
+				return next(ctx, call)
+			},
+		},
+	})
+
+	srv.FailNext("update_display_currency", wallet.ErrInternal)
+	_, err := c.UpdateDisplayCurrency(context.Background(), &wallet.UpdateDisplayCurrencyInput{DisplayCurrency: "USD"})
+	if werr, ok := err.(wallet.Error); !ok || werr.Code != wallet.ErrInternal {
+		t.Fatalf("got %v, want %s", err, wallet.ErrInternal)
+	}
+	if n := len(srv.Calls()); n != 1 {
+		t.Fatalf("got %d calls, want 1", n)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	cached := &wallet.ListBanksOutput{Banks: []wallet.Bank{{Name: "Cached"}}}
+	c := wallet.New(&wallet.Options{
+		Interceptors: []wallet.Interceptor{
+			func(ctx context.Context, call *wallet.Call, next wallet.Next) error {
+				*call.Output.(**wallet.ListBanksOutput) = cached
+				return nil
+			},
+		},
+	})
+	output, err := c.ListBanks(context.Background(), &wallet.ListBanksInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != cached {
+		t.Fatalf("expected the cached output")
+	}
+}
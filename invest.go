@@ -0,0 +1,292 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrFeeChanged is returned by [InvestmentFlow.Submit] when the fee of the investment changed
+// since it was quoted, for instance because the fund's subscription fee was updated or the
+// voucher expired. Get a new quote and have it approved again.
+var ErrFeeChanged = errors.New("wallet: fee changed since the quote")
+
+// InvestmentFlow runs the calls it takes to place an investment, in order:
+//
+//  1. [Client.ListFundsForSubscription], to check the fund class is open to the account,
+//  2. [Client.ListInvestConsents], to know the consents the investor must give,
+//  3. [Client.GetPreviewInvest], to preview the fee,
+//  4. [Client.GetVoucher], when a voucher is applied,
+//  5. [Client.CreateInvestmentRequest], with the consents and the voucher of the quote,
+//  6. [Client.GetClientAccountRequestConfirmation], to download the confirmation.
+//
+// The first four steps build an [InvestmentQuote] to approve before submitting it:
+//
+//	flow := client.NewInvestmentFlow(accountID, fundID, 1, amount).WithVoucher("WELCOME")
+//	quote, err := flow.Quote(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	// show quote.Consents and quote.FeeAmount to the investor
+//	flow.AcceptConsents("IM", "highRisk")
+//	result, err := flow.Submit(ctx, quote)
+//
+// Use [InvestmentFlow.Run] to quote and submit in one call, with a callback approving the quote.
+// A flow places at most one investment: every submission carries the same idempotency key, so
+// calling Submit again after an error whose outcome is unknown, such as a timeout, returns the
+// request already created with [InvestmentResult.Existing] set. Use a new flow for another
+// investment. An InvestmentFlow must not be used concurrently.
+type InvestmentFlow struct {
+	client            *Client
+	accountID         string
+	fundID            string
+	fundClassSequence int
+	amount            Decimal
+	voucherCode       string
+	accepted          map[string]bool
+	format            DocumentFormat
+	// idempotencyKey is generated by the first Submit and reused by the following ones.
+	idempotencyKey string
+	// sent is the quote the investment request was sent with, nil until Submit sends it.
+	sent *InvestmentQuote
+}
+
+// NewInvestmentFlow returns a flow investing amount in the class of a fund, from the account.
+func (c *Client) NewInvestmentFlow(accountID string, fundID string, fundClassSequence int, amount Decimal) *InvestmentFlow {
+	return &InvestmentFlow{
+		client:            c,
+		accountID:         accountID,
+		fundID:            fundID,
+		fundClassSequence: fundClassSequence,
+		amount:            amount,
+		accepted:          map[string]bool{},
+		format:            DocumentFormatPDF,
+	}
+}
+
+// WithVoucher applies a voucher code to the investment. Without one, the flow applies the
+// default voucher of the preview, if any.
+func (f *InvestmentFlow) WithVoucher(code string) *InvestmentFlow {
+	f.voucherCode = code
+	return f
+}
+
+// AcceptConsents records the consents given by the investor, by name. All the consents of the
+// quote must be accepted before it is submitted.
+func (f *InvestmentFlow) AcceptConsents(names ...string) *InvestmentFlow {
+	for _, name := range names {
+		f.accepted[name] = true
+	}
+	return f
+}
+
+// WithConfirmationFormat sets the format of the confirmation document, [DocumentFormatPDF] by default.
+func (f *InvestmentFlow) WithConfirmationFormat(format DocumentFormat) *InvestmentFlow {
+	f.format = format
+	return f
+}
+
+// InvestmentQuote holds the outcome of the steps run before an investment is submitted.
+type InvestmentQuote struct {
+	// AccountID specifies the investing account.
+	AccountID string
+
+	// Fund is the fund invested in, as listed by [Client.ListFundsForSubscription].
+	Fund Fund
+
+	// Class is the fund class invested in.
+	Class FundClass
+
+	// Amount is the amount to invest, before fees.
+	Amount Decimal
+
+	// Consents lists the consents the investor must accept with [InvestmentFlow.AcceptConsents].
+	Consents []Consent
+
+	// Preview is the fee preview of the investment, without voucher.
+	Preview GetPreviewInvestOutput
+
+	// Voucher is the voucher applied to the investment, either given with
+	// [InvestmentFlow.WithVoucher] or the default voucher of the preview. Nil without voucher.
+	Voucher *GetVoucherOutput
+
+	// FeePercentage is the subscription fee percentage applied, after the voucher discount.
+	FeePercentage Decimal
+
+	// FeeAmount is the subscription fee charged.
+	FeeAmount Decimal
+
+	// PostFeeAmount is the amount invested after the fee.
+	PostFeeAmount Decimal
+}
+
+// VoucherCode returns the code of the applied voucher, empty without voucher.
+func (q *InvestmentQuote) VoucherCode() string {
+	if q.Voucher == nil {
+		return ""
+	}
+	return q.Voucher.Code
+}
+
+// InvestmentResult is the outcome of a submitted investment.
+type InvestmentResult struct {
+	// RequestID specifies the identifier of the created investment request.
+	RequestID string
+
+	// Existing reports whether the request was previously created with the same idempotency key.
+	Existing bool
+
+	// Quote is the quote the investment was submitted with.
+	Quote *InvestmentQuote
+
+	// Confirmation is the confirmation document of the request.
+	Confirmation *GetClientAccountRequestConfirmationOutput
+}
+
+// Quote runs the steps before the investment is submitted and returns the quote to approve.
+func (f *InvestmentFlow) Quote(ctx context.Context) (*InvestmentQuote, error) {
+	funds, err := f.client.ListFundsForSubscription(ctx, &ListFundsForSubscriptionInput{AccountID: f.accountID})
+	if err != nil {
+		return nil, err
+	}
+	q := &InvestmentQuote{AccountID: f.accountID, Amount: f.amount}
+	if !f.findClass(funds.Funds, q) {
+		return nil, Error{
+			Code:    ErrMissingResource,
+			Message: fmt.Sprintf("fund %q class %d is not open for subscription to account %q", f.fundID, f.fundClassSequence, f.accountID),
+			Details: []ErrorDetail{{Field: "fundId", Code: ErrMissingResource, Message: "fund class is not open for subscription"}},
+			APIName: "list_funds_for_subscription",
+		}
+	}
+
+	consents, err := f.client.ListInvestConsents(ctx, &ListInvestConsentsInput{AccountID: f.accountID, FundID: f.fundID, FundClassSequence: f.fundClassSequence})
+	if err != nil {
+		return nil, err
+	}
+	q.Consents = consents.Consents
+
+	preview, err := f.client.GetPreviewInvest(ctx, &GetPreviewInvestInput{AccountID: f.accountID, FundID: f.fundID, FundClassSequence: f.fundClassSequence, Amount: f.amount})
+	if err != nil {
+		return nil, err
+	}
+	q.Preview = *preview
+	q.FeePercentage, q.FeeAmount, q.PostFeeAmount = preview.AppliedSubscriptionFeePercentage, preview.FeeAmount, preview.PostFeeAmount
+
+	code := f.voucherCode
+	if code == "" && preview.DefaultVoucher != nil && preview.DefaultVoucher.Valid {
+		code = preview.DefaultVoucher.Code
+	}
+	if code != "" {
+		voucher, err := f.client.GetVoucher(ctx, &GetVoucherInput{AccountID: f.accountID, FundID: f.fundID, FundClassSequence: f.fundClassSequence, Amount: f.amount, VoucherCode: &code})
+		if err != nil {
+			return nil, err
+		}
+		if !voucher.Valid {
+			return nil, Error{
+				Code:    ErrInvalidParameter,
+				Message: fmt.Sprintf("voucher %q is not valid", code),
+				Details: []ErrorDetail{{Field: "voucherCode", Code: ErrInvalidParameter, Message: "voucher is not valid"}},
+				APIName: "get_voucher",
+			}
+		}
+		q.Voucher = voucher
+		q.FeePercentage, q.FeeAmount, q.PostFeeAmount = voucher.AppliedSubscriptionFeePercentage, voucher.FeeAmount, voucher.PostFeeAmount
+	}
+	return q, nil
+}
+
+func (f *InvestmentFlow) findClass(funds []Fund, q *InvestmentQuote) bool {
+	for _, fund := range funds {
+		if fund.ID != f.fundID {
+			continue
+		}
+		for _, class := range fund.Classes {
+			if class.Sequence == f.fundClassSequence {
+				q.Fund, q.Class = fund, class
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Submit creates the investment request of an approved quote and downloads its confirmation.
+//
+// Submit quotes the investment again and fails with [ErrFeeChanged] when the fee or the amount
+// invested after it differs from the approved quote. Once the request was sent, Submit skips
+// that check and sends the request again with the quote it was first sent with, whatever quote
+// is given, so that the server returns the request already created. It fails with [ErrMissingParameter] when a consent of the quote was not
+// accepted. When the request is created but its confirmation cannot be downloaded, Submit returns
+// the result with the request ID along with the error, so the investment is not sent twice.
+//
+// Submit sends the request with the idempotency key of the flow, unless ctx carries one set with
+// [WithIdempotencyKey], so that calling it again after a failure does not invest twice.
+func (f *InvestmentFlow) Submit(ctx context.Context, quote *InvestmentQuote) (*InvestmentResult, error) {
+	if f.sent != nil {
+		quote = f.sent
+	}
+	consents := make(map[string]bool, len(quote.Consents))
+	for _, consent := range quote.Consents {
+		if !f.accepted[consent.Name] {
+			detail := ErrorDetail{Field: "consents", Code: ErrMissingParameter, Message: fmt.Sprintf("consent %q is not accepted", consent.Name)}
+			return nil, Error{Code: detail.Code, Message: detail.Message, Details: []ErrorDetail{detail}, APIName: "create_investment_request"}
+		}
+		consents[consent.Name] = true
+	}
+
+	if f.sent == nil {
+		current, err := f.Quote(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if current.VoucherCode() != quote.VoucherCode() || current.FeePercentage.Cmp(quote.FeePercentage) != 0 ||
+			current.FeeAmount.Cmp(quote.FeeAmount) != 0 || current.PostFeeAmount.Cmp(quote.PostFeeAmount) != 0 {
+			return nil, fmt.Errorf("%w: %v%% (%v, %v invested) was quoted, now %v%% (%v, %v invested)", ErrFeeChanged,
+				quote.FeePercentage, quote.FeeAmount, quote.PostFeeAmount, current.FeePercentage, current.FeeAmount, current.PostFeeAmount)
+		}
+	}
+
+	if idempotencyKeyOf(ctx) == "" {
+		if f.idempotencyKey == "" {
+			key, err := NewIdempotencyKey()
+			if err != nil {
+				return nil, err
+			}
+			f.idempotencyKey = key
+		}
+		ctx = WithIdempotencyKey(ctx, f.idempotencyKey)
+	}
+	f.sent = quote
+	output, err := f.client.CreateInvestmentRequest(ctx, &CreateInvestmentRequestInput{
+		AccountID:         quote.AccountID,
+		FundID:            quote.Fund.ID,
+		FundClassSequence: quote.Class.Sequence,
+		Amount:            quote.Amount,
+		Consents:          consents,
+		VoucherCode:       quote.VoucherCode(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &InvestmentResult{RequestID: output.RequestID, Existing: output.Existing, Quote: quote}
+	result.Confirmation, err = f.client.GetClientAccountRequestConfirmation(ctx, &GetClientAccountRequestConfirmationInput{
+		AccountID: quote.AccountID,
+		RequestID: output.RequestID,
+		Format:    f.format,
+	})
+	return result, err
+}
+
+// Run quotes the investment, calls approve with the quote and submits it when approve returns
+// nil. approve may accept the consents of the quote with [InvestmentFlow.AcceptConsents]; an
+// error returned by approve aborts the flow and is returned as is.
+func (f *InvestmentFlow) Run(ctx context.Context, approve func(*InvestmentQuote) error) (*InvestmentResult, error) {
+	quote, err := f.Quote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := approve(quote); err != nil {
+		return nil, err
+	}
+	return f.Submit(ctx, quote)
+}
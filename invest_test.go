@@ -0,0 +1,124 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func commandCalls(srv *wallettest.Server) int {
+	n := 0
+	for _, call := range srv.Calls() {
+		if call.Kind == "command" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestInvestmentFlow(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	ctx := context.Background()
+
+	flow := c.NewInvestmentFlow(wallettest.SingleAccountID, wallettest.BitcoinFundID, 1, wallet.MustParseDecimal("1000")).
+		WithVoucher(wallettest.VoucherCode)
+	result, err := flow.Run(ctx, func(q *wallet.InvestmentQuote) error {
+		if len(q.Consents) != 2 || q.Preview.FeeAmount.String() != "20.00" {
+			t.Fatalf("unexpected quote %+v", q)
+		}
+		for _, consent := range q.Consents {
+			flow.AcceptConsents(consent.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Quote.VoucherCode() != wallettest.VoucherCode || result.Quote.FeeAmount.String() != "10.00" || result.Quote.PostFeeAmount.String() != "990.00" {
+		t.Fatalf("unexpected quote %+v", result.Quote)
+	}
+	if result.RequestID == "" || result.Confirmation == nil || len(result.Confirmation.Bytes) == 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	requests, err := c.ListClientAccountRequests(ctx, &wallet.ListClientAccountRequestsInput{AccountID: wallettest.SingleAccountID, RequestID: &result.RequestID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := requests.Requests[0]; r.FeeAmount.String() != "10.00" || r.VoucherCode == nil || *r.VoucherCode != wallettest.VoucherCode {
+		t.Fatalf("expected the quoted fee and voucher to be carried to the request, got %+v", r)
+	}
+
+	aborted := errors.New("declined")
+	if _, err := flow.Run(ctx, func(*wallet.InvestmentQuote) error { return aborted }); err != aborted {
+		t.Fatalf("got %v, want %v", err, aborted)
+	}
+	if n := commandCalls(srv); n != 1 {
+		t.Fatalf("got %d commands, want 1", n)
+	}
+}
+
+func TestInvestmentFlowSubmit(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	ctx := context.Background()
+
+	flow := c.NewInvestmentFlow(wallettest.SingleAccountID, wallettest.BitcoinFundID, 1, wallet.MustParseDecimal("1000"))
+	quote, err := flow.Quote(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := flow.Submit(ctx, quote); !errors.Is(err, wallet.Error{Code: wallet.ErrMissingParameter}) {
+		t.Fatalf("got %v, want %s", err, wallet.ErrMissingParameter)
+	}
+
+	flow.AcceptConsents("IM", "highRisk")
+	var fee wallet.Decimal
+	srv.State(func(seed *wallettest.Seed) {
+		fee = seed.Funds[0].Classes[0].SubscriptionFee
+		seed.Funds[0].Classes[0].SubscriptionFee = wallet.MustParseDecimal("3")
+	})
+	if _, err := flow.Submit(ctx, quote); !errors.Is(err, wallet.ErrFeeChanged) {
+		t.Fatalf("got %v, want %v", err, wallet.ErrFeeChanged)
+	}
+	if n := commandCalls(srv); n != 0 {
+		t.Fatalf("got %d commands, want none", n)
+	}
+
+	// the investment is placed but its response is lost, submitting again returns it even
+	// though the fee changed in between.
+	srv.State(func(seed *wallettest.Seed) {
+		seed.Funds[0].Classes[0].SubscriptionFee = fee
+	})
+	c = srv.NewClient(&wallet.Options{RetryPolicy: &wallet.RetryPolicy{MaxServerErrorAttempts: 1}})
+	flow = c.NewInvestmentFlow(wallettest.SingleAccountID, wallettest.BitcoinFundID, 1, wallet.MustParseDecimal("1000")).
+		AcceptConsents("IM", "highRisk")
+	before := countRequests(srv, wallettest.SingleAccountID)
+	srv.Inject(wallettest.Fault{Name: "create_investment_request", Code: wallet.ErrInternal, AfterHandling: true})
+	if _, err := flow.Submit(ctx, quote); !errors.Is(err, wallet.Error{Code: wallet.ErrInternal}) {
+		t.Fatalf("got %v, want %s", err, wallet.ErrInternal)
+	}
+	srv.State(func(seed *wallettest.Seed) {
+		seed.Funds[0].Classes[0].SubscriptionFee = wallet.MustParseDecimal("3")
+	})
+	result, err := flow.Submit(ctx, quote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Existing {
+		t.Fatal("expected the submitted again investment to report the existing request")
+	}
+	if n := countRequests(srv, wallettest.SingleAccountID) - before; n != 1 {
+		t.Fatalf("got %d new requests, want 1", n)
+	}
+
+	_, err = c.NewInvestmentFlow(wallettest.SingleAccountID, wallettest.BitcoinFundID, 9, wallet.MustParseDecimal("1000")).Quote(ctx)
+	if !errors.Is(err, wallet.Error{Code: wallet.ErrMissingResource}) {
+		t.Fatalf("got %v, want %s", err, wallet.ErrMissingResource)
+	}
+}
@@ -2,25 +2,15 @@ package wallet
 
 import (
 	"bytes"
-	"crypto"
-	"crypto/ecdsa"
+	"context"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/sha256"
-	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
-	"encoding/pem"
 	"fmt"
-	"math/big"
 	"time"
 )
 
-const (
-	es256 string = "ES256"
-	rs256 string = "RS256"
-)
-
 type tokenHeader struct {
 	Alg string `json:"alg"`
 	Typ string `json:"typ"`
@@ -69,90 +59,59 @@ func newToken(keyID string, uri string, body []byte, ttl time.Duration, shouldCl
 	}, nil
 }
 
+// signAndFormat signs the token with a PEM encoded private key and returns the compact JWT.
 func (t *token) signAndFormat(privateKeyPEM []byte) (string, error) {
-	// clean up the private key from memory
-	defer func() {
-		if !t.shouldCleanKey {
-			return
-		}
-		for i := range privateKeyPEM {
-			privateKeyPEM[i] = 0
-
-		}
-	}()
-
-	privateKeyBlock, _ := pem.Decode(privateKeyPEM)
-	if privateKeyBlock == nil {
-		return "", fmt.Errorf("wallet: signAndFormat: private key must be in PEM format.")
+	signer := &pemSigner{
+		keyID:          t.Payload.Kid,
+		privateKeyPEM:  privateKeyPEM,
+		shouldCleanKey: t.shouldCleanKey,
 	}
-	defer func() {
-		for i := range privateKeyBlock.Bytes {
-			privateKeyBlock.Bytes[i] = 0
-		}
-	}()
+	defer signer.discard()
+	return t.sign(context.Background(), signer)
+}
 
-	var privateKeyAny any
-	var err error
-	// try EC
-	privateKeyAny, err = x509.ParseECPrivateKey(privateKeyBlock.Bytes)
+// sign signs the token with signer and returns the compact JWT. The key ID and algorithm
+// returned by signer override the token's kid and alg.
+func (t *token) sign(ctx context.Context, signer Signer) (string, error) {
+	keyID, alg, err := signer.SigningKey(ctx)
 	if err != nil {
-		// try RSA
-		privateKeyAny, err = x509.ParsePKCS1PrivateKey(privateKeyBlock.Bytes)
-		if err != nil {
-			privateKeyAny, err = x509.ParsePKCS8PrivateKey(privateKeyBlock.Bytes)
-			if err != nil {
-				return "", fmt.Errorf("wallet: signAndFormat: unable to deduce private key type. Valid key would either be EC or RSA.")
-			}
-		}
+		return "", err
 	}
+	return t.signWith(ctx, signer, keyID, alg)
+}
 
-	var jsonBuffer bytes.Buffer
-	if err := json.NewEncoder(&jsonBuffer).Encode(t.Payload); err != nil {
-		return "", fmt.Errorf("wallet: signAndFormat: %v", err)
-	}
-	encodedPayload := base64.RawURLEncoding.EncodeToString(jsonBuffer.Bytes())
-	jsonBuffer.Reset()
+// signWith signs the token with the key keyID of signer, using alg.
+func (t *token) signWith(ctx context.Context, signer Signer, keyID string, alg string) (string, error) {
+	t.Header.Alg = alg
+	t.Payload.Kid = keyID
 
-	signingString := ""
-	signatureB := []byte{}
-	switch key := privateKeyAny.(type) {
-	case *ecdsa.PrivateKey:
-		t.Header.Alg = es256
-		if err := json.NewEncoder(&jsonBuffer).Encode(t.Header); err != nil {
-			return "", fmt.Errorf("wallet: signAndFormat: %v", err)
+	var encodedHeader string
+	if s, ok := signer.(*keySigner); ok && s.alg == alg {
+		encodedHeader = s.header
+	} else {
+		var err error
+		if encodedHeader, err = encodeSegment(t.Header); err != nil {
+			return "", err
 		}
-		encodedHeader := base64.RawURLEncoding.EncodeToString(jsonBuffer.Bytes())
-		signingString = encodedHeader + "." + encodedPayload
-		hashed := sha256.Sum256([]byte(signingString))
-		jsonBuffer.Reset()
-		signatureB, err = ecdsa.SignASN1(rand.Reader, key, hashed[:])
-		if err != nil {
-			return "", fmt.Errorf("wallet: signAndFormat: failed to sign with EC key. err=%v", err)
-		}
-		key.D = big.NewInt(0)
-		key.X = big.NewInt(0)
-		key.Y = big.NewInt(0)
-		key = nil
-	case *rsa.PrivateKey:
-		t.Header.Alg = rs256
-		if err := json.NewEncoder(&jsonBuffer).Encode(t.Header); err != nil {
-			return "", fmt.Errorf("wallet: signAndFormat: %v", err)
-		}
-		encodedHeader := base64.RawURLEncoding.EncodeToString(jsonBuffer.Bytes())
-		jsonBuffer.Reset()
-		signingString = encodedHeader + "." + encodedPayload
-		hashed := sha256.Sum256([]byte(signingString))
-		signatureB, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
-		if err != nil {
-			return "", fmt.Errorf("wallet: signAndFormat: failed to sign with RSA key. err=%v", err)
-		}
-		key.D = big.NewInt(0)
-		key.N = big.NewInt(0)
-		key = nil
-	default:
-		return "", fmt.Errorf("wallet: signAndFormat: unable to cast private key type. Valid key would either be *[rsa.PrivateKey] or *[ecdsa.PrivateKey].")
 	}
-	privateKeyAny = nil
+	encodedPayload, err := encodeSegment(t.Payload)
+	if err != nil {
+		return "", err
+	}
 
+	signingString := encodedHeader + "." + encodedPayload
+	signatureB, err := signer.Sign(ctx, keyID, signingInput(alg, signingString))
+	if err != nil {
+		return "", err
+	}
 	return signingString + "." + base64.RawURLEncoding.EncodeToString(signatureB), nil
 }
+
+// encodeSegment returns v JSON and base64url encoded as a token segment.
+func encodeSegment(v any) (string, error) {
+	var jsonBuffer bytes.Buffer
+	if err := json.NewEncoder(&jsonBuffer).Encode(v); err != nil {
+		return "", fmt.Errorf("wallet: signAndFormat: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(jsonBuffer.Bytes()), nil
+}
@@ -0,0 +1,42 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// benchmarkSign signs a token per iteration with the signer returned by newSigner.
+func benchmarkSign(b *testing.B, newSigner func() Signer) {
+	body := []byte(`{"name":"list_client_accounts","payload":{}}`)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			token, err := newToken(testKeyID, "/query", body, 10*time.Second, false)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := token.sign(context.Background(), newSigner()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkSignPerRequest(b *testing.B) {
+	privateKeyPEM := newTestECKeyPEM(b)
+	benchmarkSign(b, func() Signer {
+		return &pemSigner{keyID: testKeyID, privateKeyPEM: privateKeyPEM}
+	})
+}
+
+func BenchmarkSignCached(b *testing.B) {
+	signer, err := newKeySigner(testKeyID, newTestECKeyPEM(b), nil, "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkSign(b, func() Signer {
+		return signer
+	})
+}
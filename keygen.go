@@ -0,0 +1,177 @@
+package wallet
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// KeyTypeEC is an EC key on the P-256 curve, recommended by Halogen.
+	KeyTypeEC string = "ec"
+	// KeyTypeRSA is a 4096-bit RSA key.
+	KeyTypeRSA string = "rsa"
+)
+
+// rsaKeyBits is the only RSA key length accepted for API keys.
+const rsaKeyBits = 4096
+
+// GenerateKeyPairInput specifies the key pair generated by [GenerateKeyPair].
+type GenerateKeyPairInput struct {
+	// KeyType specifies the type of the key.
+	//
+	// Value can be one of [KeyTypeEC] or [KeyTypeRSA]. Optional, defaulted to [KeyTypeEC].
+	KeyType string
+
+	// Subject specifies the subject of the CSR, such as its common name, organization,
+	// organizational unit, locality, state and country.
+	//
+	// Optional.
+	Subject pkix.Name
+
+	// Passphrase encrypts the private key as an "ENCRYPTED PRIVATE KEY" PEM block, using PBES2
+	// with PBKDF2-HMAC-SHA256 and AES-256-CBC.
+	//
+	// Optional, if not set, the private key is written unencrypted.
+	Passphrase []byte
+}
+
+// KeyPair is a private key and the Certificate Signing Request (CSR) of its public key, to be
+// uploaded in Settings > API Keys of the Halogen Wallet to create an API key.
+type KeyPair struct {
+	// KeyType is the type of the key, either [KeyTypeEC] or [KeyTypeRSA].
+	KeyType string
+
+	// PrivateKeyPEM is the PEM encoded PKCS #8 private key, encrypted when a passphrase was given.
+	PrivateKeyPEM []byte
+
+	// CSRPEM is the PEM encoded CSR, signed by the private key.
+	CSRPEM []byte
+}
+
+// GenerateKeyPair generates an EC P-256 or RSA-4096 private key and its CSR, replacing the
+// OpenSSL recipes of the Makefile. The CSR is checked with [CheckCSR] before it is returned,
+// so that it is not rejected when uploaded.
+func GenerateKeyPair(input *GenerateKeyPairInput) (*KeyPair, error) {
+	var in GenerateKeyPairInput
+	if input != nil {
+		in = *input
+	}
+	if in.KeyType == "" {
+		in.KeyType = KeyTypeEC
+	}
+
+	var key crypto.Signer
+	var err error
+	switch in.KeyType {
+	case KeyTypeEC:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyTypeRSA:
+		key, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		return nil, fmt.Errorf("wallet: GenerateKeyPair: unsupported key type %q. Valid key type would either be %q or %q.", in.KeyType, KeyTypeEC, KeyTypeRSA)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer clearPrivateKey(key)
+
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: in.Subject}, key)
+	if err != nil {
+		return nil, err
+	}
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+	csr, err := checkCSR(csrPEM)
+	if err != nil {
+		return nil, err
+	}
+	if !key.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(csr.PublicKey) {
+		return nil, Error{Code: ErrInvalidCSR, Message: "CSR public key does not match the private key"}
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(der)
+	block := &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	if len(in.Passphrase) > 0 {
+		if block, err = encryptPKCS8PrivateKey(der, in.Passphrase); err != nil {
+			return nil, err
+		}
+	}
+	return &KeyPair{KeyType: in.KeyType, PrivateKeyPEM: pem.EncodeToMemory(block), CSRPEM: csrPEM}, nil
+}
+
+// WriteFiles writes the private key to privateKeyPath with 0600 permissions and the CSR to
+// csrPath, creating their directories with 0700 permissions. It does not overwrite an
+// existing private key, and removes the private key it wrote when the CSR cannot be written,
+// so that WriteFiles can be called again.
+func (k *KeyPair) WriteFiles(privateKeyPath string, csrPath string) error {
+	for _, path := range []string{privateKeyPath, csrPath} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(privateKeyPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(k.PrivateKeyPEM)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.WriteFile(csrPath, k.CSRPEM, 0o644)
+	}
+	if err != nil {
+		os.Remove(privateKeyPath)
+	}
+	return err
+}
+
+// CheckCSR checks that csrPEM is a CSR accepted by Halogen when creating an API key: a PEM
+// encoded CSR of an EC P-256 or RSA-4096 public key, signed by the matching private key.
+//
+// The returned [Error] has the code the server would reject the CSR with, one of
+// [ErrInvalidCSRFormat], [ErrInvalidCSR], [ErrInvalidCSRKeyType], [ErrInvalidCSRKeyLength],
+// [ErrInvalidCSREllipticCurve] or [ErrInvalidCSRSignature].
+func CheckCSR(csrPEM []byte) error {
+	_, err := checkCSR(csrPEM)
+	return err
+}
+
+func checkCSR(csrPEM []byte) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, Error{Code: ErrInvalidCSRFormat, Message: "CSR must be a PEM encoded CERTIFICATE REQUEST"}
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, Error{Code: ErrInvalidCSR, Message: fmt.Sprintf("CSR cannot be parsed: %v", err)}
+	}
+	switch key := csr.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, Error{Code: ErrInvalidCSREllipticCurve, Message: fmt.Sprintf("EC key must use the P-256 curve, got %s", key.Curve.Params().Name)}
+		}
+	case *rsa.PublicKey:
+		if key.N.BitLen() != rsaKeyBits {
+			return nil, Error{Code: ErrInvalidCSRKeyLength, Message: fmt.Sprintf("RSA key must be %d bits long, got %d", rsaKeyBits, key.N.BitLen())}
+		}
+	default:
+		return nil, Error{Code: ErrInvalidCSRKeyType, Message: fmt.Sprintf("CSR key must either be EC P-256 or RSA-4096, got %s", csr.PublicKeyAlgorithm)}
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, Error{Code: ErrInvalidCSRSignature, Message: fmt.Sprintf("CSR signature is invalid: %v", err)}
+	}
+	return csr, nil
+}
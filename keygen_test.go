@@ -0,0 +1,152 @@
+package wallet_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func TestGenerateKeyPair(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+
+	for _, keyType := range []string{wallet.KeyTypeEC, wallet.KeyTypeRSA} {
+		pair, err := wallet.GenerateKeyPair(&wallet.GenerateKeyPairInput{
+			KeyType: keyType,
+			Subject: pkix.Name{CommonName: "example.com", Organization: []string{"Organization"}, Country: []string{"MY"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, _ := pem.Decode(pair.CSRPEM)
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if csr.Subject.CommonName != "example.com" || csr.Subject.Country[0] != "MY" {
+			t.Fatalf("unexpected subject %s", csr.Subject)
+		}
+
+		// the key signs requests accepted for the public key of the CSR.
+		srv.RegisterKey(keyType+"-key", csr.PublicKey)
+		c := srv.NewClient(&wallet.Options{})
+		if err := c.SetCachedCredentials(keyType+"-key", pair.PrivateKeyPEM); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.ListBanks(context.Background(), &wallet.ListBanksInput{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := wallet.GenerateKeyPair(&wallet.GenerateKeyPairInput{KeyType: "dsa"}); err == nil {
+		t.Fatal("expected an unsupported key type to be rejected")
+	}
+	pair, err := wallet.GenerateKeyPair(&wallet.GenerateKeyPairInput{Passphrase: []byte("correct horse")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block, _ := pem.Decode(pair.PrivateKeyPEM); block == nil || block.Type != "ENCRYPTED PRIVATE KEY" {
+		t.Fatalf("expected an encrypted private key, got %s", pair.PrivateKeyPEM)
+	}
+	block, _ := pem.Decode(pair.CSRPEM)
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.RegisterKey("encrypted-key", csr.PublicKey)
+	c := srv.NewClient(&wallet.Options{PassphraseFunc: func(keyID string) ([]byte, error) {
+		return []byte("correct horse"), nil
+	}})
+	c.SetCredentials("encrypted-key", pair.PrivateKeyPEM)
+	if _, err := c.ListBanks(context.Background(), &wallet.ListBanksInput{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyPairWriteFiles(t *testing.T) {
+	pair, err := wallet.GenerateKeyPair(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	keyPath, csrPath := filepath.Join(dir, ".key", "ec_private_key.pem"), filepath.Join(dir, ".key", "ec_csr.pem")
+	if err := pair.WriteFiles(keyPath, csrPath); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("got private key permissions %o, want 600", perm)
+	}
+	if b, err := os.ReadFile(csrPath); err != nil || wallet.CheckCSR(b) != nil {
+		t.Fatalf("expected a valid CSR to be written, got %v", err)
+	}
+	if err := pair.WriteFiles(keyPath, csrPath); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("expected an existing private key not to be overwritten, got %v", err)
+	}
+	// the CSR path is a directory, the private key is removed so that it can be written again.
+	keyPath = filepath.Join(dir, "other_private_key.pem")
+	if err := pair.WriteFiles(keyPath, dir); err == nil {
+		t.Fatal("expected writing the CSR to a directory to fail")
+	}
+	if _, err := os.Stat(keyPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the private key to be removed, got %v", err)
+	}
+	if err := pair.WriteFiles(keyPath, filepath.Join(dir, "other_csr.pem")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckCSR(t *testing.T) {
+	newCSR := func(key crypto.Signer) []byte {
+		der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+	}
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	rsa2048, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, ed, _ := ed25519.GenerateKey(rand.Reader)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tampered := newCSR(p256)
+	block, _ := pem.Decode(tampered)
+	block.Bytes[len(block.Bytes)-1] ^= 0xff
+	tampered = pem.EncodeToMemory(block)
+
+	tests := []struct {
+		csr  []byte
+		code string
+	}{
+		{[]byte("not a CSR"), wallet.ErrInvalidCSRFormat},
+		{pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: []byte{1, 2, 3}}), wallet.ErrInvalidCSR},
+		{newCSR(p384), wallet.ErrInvalidCSREllipticCurve},
+		{newCSR(rsa2048), wallet.ErrInvalidCSRKeyLength},
+		{newCSR(ed), wallet.ErrInvalidCSRKeyType},
+		{tampered, wallet.ErrInvalidCSRSignature},
+	}
+	for _, tt := range tests {
+		if err := wallet.CheckCSR(tt.csr); !errors.Is(err, wallet.Error{Code: tt.code}) {
+			t.Errorf("got %v, want %s", err, tt.code)
+		}
+	}
+	if err := wallet.CheckCSR(newCSR(p256)); err != nil {
+		t.Fatal(err)
+	}
+}
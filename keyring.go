@@ -0,0 +1,350 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNoValidKey is returned when a [KeyRing] holds no key that is active, unexpired and not
+// rejected by the server.
+var ErrNoValidKey = errors.New("wallet: no valid key in the key ring")
+
+// Key is an API key held by a [KeyRing]. Exactly one of PrivateKeyPEM and PrivateKey is set.
+type Key struct {
+	// ID specifies the key identifier issued by Halogen.
+	ID string
+
+	// PrivateKeyPEM specifies the PEM encoded EC, RSA or Ed25519 private key. It is parsed once
+	// by [KeyRing.Add] and may be cleared by the caller afterwards.
+	PrivateKeyPEM []byte
+
+	// Passphrase decrypts PrivateKeyPEM when it is an encrypted PKCS #8 key, see
+	// [Options.PassphraseFunc].
+	//
+	// Optional.
+	Passphrase []byte
+
+	// PrivateKey specifies the private key when it is not held in memory, for instance in an
+	// HSM or a cloud KMS, see [NewCryptoSigner].
+	PrivateKey crypto.Signer
+
+	// Algorithm selects the JWT algorithm of the key, see [Options.Algorithm].
+	//
+	// Optional, defaulted to the algorithm of the key.
+	Algorithm string
+
+	// NotBefore specifies when the key becomes active.
+	//
+	// Optional, if not set, the key is active as soon as it is added.
+	NotBefore time.Time
+
+	// NotAfter specifies when the key expires.
+	//
+	// Optional, if not set, the key never expires on the client side.
+	NotAfter time.Time
+}
+
+// KeyEventKind specifies what happened to a key of a [KeyRing].
+type KeyEventKind string
+
+const (
+	// KeyEventExpiring is emitted once when a key enters [KeyRingOptions.ExpiryWarning].
+	KeyEventExpiring KeyEventKind = "expiring"
+	// KeyEventExpired is emitted once when a key reaches its NotAfter time.
+	KeyEventExpired KeyEventKind = "expired"
+	// KeyEventRejected is emitted when the server rejects a key with [ErrExpiredApiKey],
+	// [ErrInvalidAuthSignature] or [ErrInvalidPublicKey]. The key is no longer used.
+	KeyEventRejected KeyEventKind = "rejected"
+	// KeyEventFailover is emitted when requests start being signed with another key.
+	KeyEventFailover KeyEventKind = "failover"
+)
+
+// KeyEvent describes a change of a key of a [KeyRing].
+type KeyEvent struct {
+	// Kind specifies what happened.
+	Kind KeyEventKind
+
+	// KeyID is the identifier of the key the event is about. For [KeyEventFailover], it is
+	// the key now used to sign requests.
+	KeyID string
+
+	// PreviousKeyID is the key used before a [KeyEventFailover], empty otherwise.
+	PreviousKeyID string
+
+	// NotAfter is the expiry time of the key, zero if it has none.
+	NotAfter time.Time
+
+	// Err is the error the server answered with for [KeyEventRejected], nil otherwise.
+	Err error
+}
+
+// KeyRingOptions specifies how a [KeyRing] reports its keys.
+type KeyRingOptions struct {
+	// ExpiryWarning specifies how long before its NotAfter time a key is reported with
+	// [KeyEventExpiring].
+	//
+	// Optional, defaulted to 14 days.
+	ExpiryWarning time.Duration
+
+	// OnEvent receives the events of the key ring. It is called synchronously, possibly from
+	// several goroutines at once, by the requests that detect the events, so it should return
+	// quickly.
+	//
+	// Optional.
+	OnEvent func(KeyEvent)
+}
+
+// KeyRing is a [Signer] holding several API keys, so that keys can be rotated without
+// downtime. Requests are signed with the preferred key when it is valid, otherwise with the
+// first valid key in the order they were added. A key is valid once it is active, until it
+// expires or the server rejects it.
+//
+// When set as [Options.Signer], a request the server answers with [ErrExpiredApiKey],
+// [ErrInvalidAuthSignature] or [ErrInvalidPublicKey] marks its key as rejected and is sent
+// again signed with the next valid key, if any. A rejected key is only used again once it is
+// added again.
+//
+// A KeyRing is safe for concurrent use, and may be shared between clients.
+type KeyRing struct {
+	expiryWarning time.Duration
+	onEvent       func(KeyEvent)
+
+	mu        sync.Mutex
+	keys      []*ringKey
+	preferred string
+	// current is the key the latest request was signed with.
+	current string
+}
+
+type ringKey struct {
+	id        string
+	notBefore time.Time
+	notAfter  time.Time
+	signer    Signer
+	alg       string
+
+	rejected bool
+	// expiring and expired report whether the matching events were emitted.
+	expiring bool
+	expired  bool
+}
+
+// NewKeyRing returns an empty key ring. opts may be nil.
+func NewKeyRing(opts *KeyRingOptions) *KeyRing {
+	var o KeyRingOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.ExpiryWarning <= 0 {
+		o.ExpiryWarning = 14 * 24 * time.Hour
+	}
+	return &KeyRing{expiryWarning: o.ExpiryWarning, onEvent: o.OnEvent}
+}
+
+// Add adds k to the ring, or replaces the key with the same ID, in which case the key is no
+// longer considered rejected. It returns an error if the private key is invalid.
+func (r *KeyRing) Add(k Key) error {
+	if k.ID == "" {
+		return fmt.Errorf("wallet: KeyRing: key ID is required.")
+	}
+	if !k.NotBefore.IsZero() && !k.NotAfter.IsZero() && !k.NotAfter.After(k.NotBefore) {
+		return fmt.Errorf("wallet: KeyRing: key %q expires before it becomes active.", k.ID)
+	}
+	var signer Signer
+	var err error
+	switch {
+	case k.PrivateKeyPEM != nil && k.PrivateKey != nil:
+		return fmt.Errorf("wallet: KeyRing: key %q must have either PrivateKeyPEM or PrivateKey, not both.", k.ID)
+	case k.PrivateKeyPEM != nil:
+		var passphrase func() ([]byte, error)
+		if k.Passphrase != nil {
+			passphrase = func() ([]byte, error) { return bytes.Clone(k.Passphrase), nil }
+		}
+		signer, err = newKeySigner(k.ID, k.PrivateKeyPEM, passphrase, k.Algorithm)
+	case k.PrivateKey != nil:
+		signer, err = NewCryptoSignerWithAlgorithm(k.ID, k.PrivateKey, k.Algorithm)
+	default:
+		return fmt.Errorf("wallet: KeyRing: key %q has no private key.", k.ID)
+	}
+	if err != nil {
+		return err
+	}
+	_, alg, err := signer.SigningKey(context.Background())
+	if err != nil {
+		return err
+	}
+
+	key := &ringKey{id: k.ID, notBefore: k.NotBefore, notAfter: k.NotAfter, signer: signer, alg: alg}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.keys {
+		if existing.id == k.ID {
+			r.keys[i] = key
+			return nil
+		}
+	}
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+// Remove removes the key keyID from the ring. Requests already signed with it are not
+// interrupted.
+func (r *KeyRing) Remove(keyID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, k := range r.keys {
+		if k.id == keyID {
+			r.keys = append(r.keys[:i], r.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetPreferred sets the key requests are signed with while it is valid. It returns an error
+// if keyID is not in the ring.
+func (r *KeyRing) SetPreferred(keyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.find(keyID) == nil {
+		return fmt.Errorf("wallet: KeyRing: key %q is not in the key ring.", keyID)
+	}
+	r.preferred = keyID
+	return nil
+}
+
+// Check emits the expiry events that are due, without waiting for the next request. Call it
+// periodically to be warned of expiring keys when requests are infrequent.
+func (r *KeyRing) Check() {
+	r.mu.Lock()
+	events := r.expiryEvents(time.Now())
+	r.mu.Unlock()
+	r.emit(events)
+}
+
+// SigningKey returns the preferred key if it is valid, otherwise the first valid key, or
+// [ErrNoValidKey] if there is none.
+func (r *KeyRing) SigningKey(ctx context.Context) (string, string, error) {
+	now := time.Now()
+	r.mu.Lock()
+	events := r.expiryEvents(now)
+	key := r.find(r.preferred)
+	if key == nil || !key.valid(now) {
+		key = nil
+		for _, k := range r.keys {
+			if k.valid(now) {
+				key = k
+				break
+			}
+		}
+	}
+	if key != nil && key.id != r.current {
+		if r.current != "" {
+			events = append(events, KeyEvent{Kind: KeyEventFailover, KeyID: key.id, PreviousKeyID: r.current, NotAfter: key.notAfter})
+		}
+		r.current = key.id
+	}
+	r.mu.Unlock()
+	r.emit(events)
+
+	if key == nil {
+		return "", "", ErrNoValidKey
+	}
+	return key.id, key.alg, nil
+}
+
+// Sign signs digest with the key keyID, even if it was rejected after SigningKey returned it.
+func (r *KeyRing) Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	r.mu.Lock()
+	key := r.find(keyID)
+	r.mu.Unlock()
+	if key == nil {
+		return nil, fmt.Errorf("wallet: KeyRing: key %q is not in the key ring.", keyID)
+	}
+	return key.signer.Sign(ctx, keyID, digest)
+}
+
+// failover sends the call again with the next valid key while the server rejects the key
+// it was signed with.
+func (r *KeyRing) failover(ctx context.Context, call *Call, next Next) error {
+	for {
+		err := next(ctx, call)
+		var werr Error
+		if err == nil || call.KeyID == "" || !errors.As(err, &werr) {
+			return err
+		}
+		switch werr.Code {
+		case ErrExpiredApiKey, ErrInvalidAuthSignature, ErrInvalidPublicKey:
+		default:
+			return err
+		}
+		if !r.reject(call.KeyID, err) {
+			return err
+		}
+	}
+}
+
+// reject marks the key keyID as rejected, and reports whether another key is valid.
+func (r *KeyRing) reject(keyID string, err error) bool {
+	now := time.Now()
+	var events []KeyEvent
+	r.mu.Lock()
+	if key := r.find(keyID); key != nil && !key.rejected {
+		key.rejected = true
+		events = append(events, KeyEvent{Kind: KeyEventRejected, KeyID: keyID, NotAfter: key.notAfter, Err: err})
+	}
+	valid := false
+	for _, k := range r.keys {
+		valid = valid || k.valid(now)
+	}
+	r.mu.Unlock()
+	r.emit(events)
+	return valid
+}
+
+// expiryEvents returns the expiry events due at now, and marks them as emitted. r.mu must be held.
+func (r *KeyRing) expiryEvents(now time.Time) []KeyEvent {
+	var events []KeyEvent
+	for _, k := range r.keys {
+		switch {
+		case k.notAfter.IsZero() || k.expired:
+		case !now.Before(k.notAfter):
+			k.expired, k.expiring = true, true
+			events = append(events, KeyEvent{Kind: KeyEventExpired, KeyID: k.id, NotAfter: k.notAfter})
+		case !k.expiring && k.notAfter.Sub(now) <= r.expiryWarning:
+			k.expiring = true
+			events = append(events, KeyEvent{Kind: KeyEventExpiring, KeyID: k.id, NotAfter: k.notAfter})
+		}
+	}
+	return events
+}
+
+func (r *KeyRing) emit(events []KeyEvent) {
+	if r.onEvent == nil {
+		return
+	}
+	for _, e := range events {
+		r.onEvent(e)
+	}
+}
+
+// find returns the key keyID, or nil. r.mu must be held.
+func (r *KeyRing) find(keyID string) *ringKey {
+	for _, k := range r.keys {
+		if k.id == keyID {
+			return k
+		}
+	}
+	return nil
+}
+
+// valid reports whether k may sign a request at now.
+func (k *ringKey) valid(now time.Time) bool {
+	return !k.rejected &&
+		(k.notBefore.IsZero() || !now.Before(k.notBefore)) &&
+		(k.notAfter.IsZero() || now.Before(k.notAfter))
+}
@@ -0,0 +1,121 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func TestKeyRingFailover(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var events []wallet.KeyEvent
+	ring := wallet.NewKeyRing(&wallet.KeyRingOptions{
+		ExpiryWarning: 7 * 24 * time.Hour,
+		OnEvent: func(e wallet.KeyEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		},
+	})
+	oldID, oldPEM := srv.GenerateKey()
+	newID, newPEM := srv.GenerateKey()
+	futureID, futurePEM := srv.GenerateKey()
+	for _, k := range []wallet.Key{
+		{ID: futureID, PrivateKeyPEM: futurePEM, NotBefore: time.Now().Add(time.Hour)},
+		{ID: newID, PrivateKeyPEM: newPEM, NotAfter: time.Now().Add(90 * 24 * time.Hour)},
+		{ID: oldID, PrivateKeyPEM: oldPEM, NotAfter: time.Now().Add(3 * 24 * time.Hour)},
+	} {
+		if err := ring.Add(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ring.SetPreferred(oldID); err != nil {
+		t.Fatal(err)
+	}
+	c := srv.NewClient(&wallet.Options{Signer: ring})
+
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Kind != wallet.KeyEventExpiring || events[0].KeyID != oldID {
+		t.Fatalf("expected the old key to be reported as expiring, got %+v", events)
+	}
+
+	srv.ExpireKey(oldID)
+	if _, err := c.CreateInvestmentRequest(ctx, &wallet.CreateInvestmentRequestInput{
+		AccountID:         wallettest.SingleAccountID,
+		FundID:            wallettest.BitcoinFundID,
+		FundClassSequence: 1,
+		Amount:            wallet.NewDecimalFromInt(1000),
+		Consents:          map[string]bool{"IM": true, "highRisk": true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	calls := srv.Calls()
+	if len(calls) != 3 || calls[1].KeyID != oldID || calls[2].KeyID != newID || calls[1].IdempotencyKey != calls[2].IdempotencyKey {
+		t.Fatalf("expected the command to be sent again with the new key, got %+v", calls)
+	}
+	if len(events) != 3 || events[1].Kind != wallet.KeyEventRejected || events[1].KeyID != oldID ||
+		!errors.Is(events[1].Err, wallet.Error{Code: wallet.ErrExpiredApiKey}) ||
+		events[2].Kind != wallet.KeyEventFailover || events[2].KeyID != newID || events[2].PreviousKeyID != oldID {
+		t.Fatalf("unexpected events %+v", events)
+	}
+
+	srv.ExpireKey(newID)
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); !errors.Is(err, wallet.Error{Code: wallet.ErrExpiredApiKey}) {
+		t.Fatalf("expected the error of the last valid key, got %v", err)
+	}
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); !errors.Is(err, wallet.ErrNoValidKey) {
+		t.Fatalf("got %v, want %v", err, wallet.ErrNoValidKey)
+	}
+
+	// adding a key again clears its rejection.
+	if err := ring.Add(wallet.Key{ID: oldID, PrivateKeyPEM: oldPEM}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); !errors.Is(err, wallet.Error{Code: wallet.ErrExpiredApiKey}) {
+		t.Fatalf("got %v, want %s", err, wallet.ErrExpiredApiKey)
+	}
+}
+
+func TestKeyRingExpiry(t *testing.T) {
+	var events []wallet.KeyEvent
+	ring := wallet.NewKeyRing(&wallet.KeyRingOptions{OnEvent: func(e wallet.KeyEvent) { events = append(events, e) }})
+	ctx := context.Background()
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+
+	if _, _, err := ring.SigningKey(ctx); !errors.Is(err, wallet.ErrNoValidKey) {
+		t.Fatalf("got %v, want %v", err, wallet.ErrNoValidKey)
+	}
+	keyID, privateKeyPEM := srv.GenerateKey()
+	if err := ring.Add(wallet.Key{ID: keyID, PrivateKeyPEM: []byte("invalid")}); err == nil {
+		t.Fatal("expected an invalid key to be rejected")
+	}
+	if err := ring.Add(wallet.Key{ID: keyID, PrivateKeyPEM: privateKeyPEM, NotBefore: time.Now(), NotAfter: time.Now().Add(-time.Hour)}); err == nil {
+		t.Fatal("expected a key expiring before it becomes active to be rejected")
+	}
+	if err := ring.SetPreferred(keyID); err == nil {
+		t.Fatal("expected an unknown key to be rejected")
+	}
+	if err := ring.Add(wallet.Key{ID: keyID, PrivateKeyPEM: privateKeyPEM, NotAfter: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	ring.Check()
+	ring.Check()
+	if len(events) != 1 || events[0].Kind != wallet.KeyEventExpired || events[0].KeyID != keyID {
+		t.Fatalf("expected a single expired event, got %+v", events)
+	}
+	if _, _, err := ring.SigningKey(ctx); !errors.Is(err, wallet.ErrNoValidKey) {
+		t.Fatalf("got %v, want %v", err, wallet.ErrNoValidKey)
+	}
+}
@@ -0,0 +1,176 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// debugLogBodyLimit is the body size logged with [Options.Debug] when LogBodyLimit is not set.
+const debugLogBodyLimit = 64 << 10
+
+// redacted replaces the values masked in logs.
+const redacted = "[REDACTED]"
+
+// redactedFields lists the JSON fields masked in logged bodies, wherever they appear: the
+// personal data of [GetClientProfileOutput], [UpdateClientProfileInput], [BankAccount] and
+// [Address], and the bank account of [CreateRedemptionRequestInput].
+var redactedFields = map[string]bool{
+	// profile
+	"nricNo":                   true,
+	"passportNo":               true,
+	"msisdn":                   true,
+	"email":                    true,
+	"nationality":              true,
+	"ethnicity":                true,
+	"otherEthnicity":           true,
+	"taxIdentificationNo":      true,
+	"authorisedPersonName":     true,
+	"authorisedPersonEmail":    true,
+	"authorisedPersonMsisdn":   true,
+	"authorisedPersonOfficeNo": true,
+	"companyRegistrationNo":    true,
+	"oldCompanyRegistrationNo": true,
+	"permanentAddress":         true,
+	"correspondenceAddress":    true,
+	// address
+	"line1":    true,
+	"line2":    true,
+	"postcode": true,
+	// bank account
+	"accountNumber":       true,
+	"accountName":         true,
+	"referenceNumber":     true,
+	"toBankAccountNumber": true,
+}
+
+// newLogger returns the logger of a client created with o: [Options.Logger] when set, a
+// debug level logger writing to the standard logger's output with [Options.Debug], the
+// default logger for the messages of [Options.DryRun], and otherwise a logger discarding
+// everything.
+func newLogger(o *Options) *slog.Logger {
+	switch {
+	case o.Logger != nil:
+		return o.Logger
+	case o.Debug:
+		return slog.New(slog.NewTextHandler(log.Writer(), &slog.HandlerOptions{Level: slog.LevelDebug}))
+	case o.DryRun:
+		return slog.Default()
+	}
+	return slog.New(slog.DiscardHandler)
+}
+
+// logInterceptor logs every call once it is done, retries included: at debug level when it
+// succeeds, at warn level when it fails.
+func (c *Client) logInterceptor(ctx context.Context, call *Call, next Next) error {
+	start := time.Now()
+	err := next(ctx, call)
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	if !c.logger.Enabled(ctx, level) {
+		return err
+	}
+	attrs := []slog.Attr{
+		slog.String("api", call.Name),
+		slog.String("kind", string(call.Kind)),
+		slog.Int("status", call.StatusCode),
+		slog.Duration("duration", time.Since(start)),
+		slog.Int("attempts", call.Attempt),
+		slog.String("keyId", call.KeyID),
+	}
+	if call.RateLimitWait > 0 {
+		attrs = append(attrs, slog.Duration("rateLimitWait", call.RateLimitWait))
+	}
+	if err != nil {
+		var werr Error
+		if errors.As(err, &werr) && werr.RequestID != "" {
+			attrs = append(attrs, slog.String("requestId", werr.RequestID))
+		}
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	c.logger.LogAttrs(ctx, level, "wallet: call", attrs...)
+	return err
+}
+
+// logBody logs a request or response body at debug level, redacted and capped to
+// [Options.LogBodyLimit]. Nothing is logged when the limit is not set.
+func (c *Client) logBody(ctx context.Context, msg string, call *Call, header http.Header, body []byte, response bool) {
+	if !c.logsBodies(ctx) {
+		return
+	}
+	// the profile is the only body whose "name" field is the name of a person
+	personName := response && call.Name == "get_client_profile"
+	c.logger.LogAttrs(ctx, slog.LevelDebug, msg,
+		slog.String("api", call.Name),
+		slog.Int("attempt", call.Attempt),
+		slog.Any("header", redactHeader(header)),
+		slog.String("body", truncate(redactBody(body, personName), c.logBodyLimit)),
+	)
+}
+
+// logsBodies reports whether bodies are logged.
+func (c *Client) logsBodies(ctx context.Context) bool {
+	return c.logBodyLimit > 0 && c.logger.Enabled(ctx, slog.LevelDebug)
+}
+
+// redactHeader returns a copy of h without the token of the Authorization header.
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	if h.Get("Authorization") != "" {
+		h.Set("Authorization", "Bearer "+redacted)
+	}
+	return h
+}
+
+// redactBody returns the JSON body with the values of redactedFields masked, and the top
+// level "name" field when personName is set. A body that is not JSON is masked entirely.
+func redactBody(body []byte, personName bool) string {
+	var v any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Sprintf("%s (%d bytes, not JSON)", redacted, len(body))
+	}
+	if object, ok := v.(map[string]any); ok && personName && object["name"] != nil {
+		object["name"] = redacted
+	}
+	b, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return redacted
+	}
+	return string(b)
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			if redactedFields[k] && value != nil {
+				v[k] = redacted
+			} else {
+				v[k] = redactValue(value)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return v
+}
+
+// truncate caps s to limit bytes.
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	return fmt.Sprintf("%s... (%d bytes truncated)", s[:limit], len(s)-limit)
+}
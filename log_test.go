@@ -0,0 +1,78 @@
+package wallet_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func TestLoggerRedaction(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	var logs bytes.Buffer
+	c := srv.NewClient(&wallet.Options{
+		Logger:       slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		LogBodyLimit: 1 << 20,
+	})
+	ctx := context.Background()
+
+	if _, err := c.GetClientProfile(ctx, &wallet.GetClientProfileInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListClientBankAccounts(ctx, &wallet.ListClientBankAccountsInput{}); err != nil {
+		t.Fatal(err)
+	}
+	out := logs.String()
+	for _, want := range []string{`"msg":"wallet: call"`, `"api":"get_client_profile"`, `"status":200`, `"attempts":1`, `"keyId":"`, `"msg":"wallet: received response"`, `Bearer [REDACTED]`, `\"bankName\":\"Maybank\"`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %s to be logged, got %s", want, out)
+		}
+	}
+	for _, secret := range []string{"Ahmad", "900101-14-5678", "ahmad@example.com", "+60123456789", "Jalan Ampang", wallettest.BankAccountNumber, "Bearer ey"} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("expected %s to be redacted, got %s", secret, out)
+		}
+	}
+}
+
+func TestLoggerLevels(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	var logs bytes.Buffer
+	c := srv.NewClient(&wallet.Options{
+		Logger:       slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn})),
+		LogBodyLimit: 1 << 20,
+	})
+	ctx := context.Background()
+
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("expected successful calls and bodies to be logged at debug level, got %s", logs.String())
+	}
+	srv.FailNext("list_banks", wallet.ErrInvalidParameter)
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if out := logs.String(); !strings.Contains(out, `"level":"WARN"`) || !strings.Contains(out, `"status":400`) || !strings.Contains(out, wallet.ErrInvalidParameter) {
+		t.Fatalf("expected the failure to be logged, got %s", out)
+	}
+
+	logs.Reset()
+	c = srv.NewClient(&wallet.Options{
+		Logger:       slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		LogBodyLimit: 16,
+	})
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), "bytes truncated") {
+		t.Fatalf("expected bodies to be truncated, got %s", logs.String())
+	}
+}
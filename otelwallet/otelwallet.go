@@ -0,0 +1,194 @@
+// Package otelwallet instruments [wallet.Client] with OpenTelemetry traces and metrics.
+//
+// It is a separate module so that the wallet package does not depend on OpenTelemetry.
+// Instrumentation is an interceptor, off until it is added to [wallet.Options.Interceptors],
+// and uses the providers it is given rather than the global ones:
+//
+//	interceptor, err := otelwallet.NewInterceptor(&otelwallet.Options{
+//		TracerProvider: tracerProvider,
+//		MeterProvider:  meterProvider,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	client := wallet.New(&wallet.Options{Interceptors: []wallet.Interceptor{interceptor}})
+//
+// Every query and command gets a client span named after its API, for instance
+// "wallet list_client_accounts", and the trace context is propagated on the outgoing request.
+// The span and the metrics cover the call as a whole, retries included.
+package otelwallet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// ScopeName is the instrumentation scope of the tracer and the meter.
+const ScopeName = "github.com/halogencapital/wallet-go/otelwallet"
+
+// Attribute keys set on spans and metrics.
+const (
+	// APINameKey is the API name of the call, for instance "list_client_accounts".
+	APINameKey = attribute.Key("wallet.api.name")
+	// CallKindKey is either "query" or "command".
+	CallKindKey = attribute.Key("wallet.call.kind")
+	// StatusCodeKey is the HTTP status code of the last response.
+	StatusCodeKey = attribute.Key("http.response.status_code")
+	// ErrorCodeKey is the [wallet.Error] code of a failed call, or one of "transport", "decode"
+	// and "other" for failures without a code.
+	ErrorCodeKey = attribute.Key("wallet.error.code")
+	// AttemptsKey is the number of times the request was sent.
+	AttemptsKey = attribute.Key("wallet.attempts")
+	// RateLimitWaitKey is the time spent waiting on [wallet.Options.RateLimiter], in seconds.
+	RateLimitWaitKey = attribute.Key("wallet.rate_limit.wait")
+	// RateLimitedAttemptsKey is the number of attempts answered with HTTP 429.
+	RateLimitedAttemptsKey = attribute.Key("wallet.rate_limited_attempts")
+)
+
+// Options specifies the providers used by the interceptor.
+type Options struct {
+	// TracerProvider creates the tracer of the spans.
+	//
+	// Optional, if not set, no span is recorded.
+	TracerProvider trace.TracerProvider
+
+	// MeterProvider creates the meter of the metrics.
+	//
+	// Optional, if not set, no metric is recorded.
+	MeterProvider metric.MeterProvider
+
+	// Propagator injects the trace context into the headers of the outgoing requests.
+	//
+	// Optional, defaulted to the W3C Trace Context propagator.
+	Propagator propagation.TextMapPropagator
+}
+
+// instruments holds the metrics recorded by the interceptor.
+type instruments struct {
+	duration    metric.Float64Histogram
+	errors      metric.Int64Counter
+	rateLimited metric.Int64Counter
+	signing     metric.Float64Histogram
+}
+
+// NewInterceptor returns an interceptor recording a span and metrics for every call:
+//
+//   - wallet.client.duration, a histogram of the call latency in seconds, retries included,
+//   - wallet.client.errors, a counter of failed calls by [ErrorCodeKey],
+//   - wallet.client.rate_limited, a counter of the responses with HTTP 429,
+//   - wallet.client.signing.duration, a histogram of the time spent signing a call, in seconds.
+//
+// Add it to [wallet.Options.Interceptors] first so that its span is the parent of the work
+// done by the other interceptors.
+func NewInterceptor(opts *Options) (wallet.Interceptor, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.TracerProvider == nil {
+		o.TracerProvider = tracenoop.NewTracerProvider()
+	}
+	if o.MeterProvider == nil {
+		o.MeterProvider = metricnoop.NewMeterProvider()
+	}
+	if o.Propagator == nil {
+		o.Propagator = propagation.TraceContext{}
+	}
+	tracer := o.TracerProvider.Tracer(ScopeName)
+	meter := o.MeterProvider.Meter(ScopeName)
+
+	var m instruments
+	var err error
+	if m.duration, err = meter.Float64Histogram("wallet.client.duration",
+		metric.WithDescription("Duration of the calls to the Halogen Wallet API, retries included."),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if m.errors, err = meter.Int64Counter("wallet.client.errors",
+		metric.WithDescription("Number of failed calls to the Halogen Wallet API, by error code."),
+		metric.WithUnit("{call}")); err != nil {
+		return nil, err
+	}
+	if m.rateLimited, err = meter.Int64Counter("wallet.client.rate_limited",
+		metric.WithDescription("Number of responses of the Halogen Wallet API with HTTP 429."),
+		metric.WithUnit("{response}")); err != nil {
+		return nil, err
+	}
+	if m.signing, err = meter.Float64Histogram("wallet.client.signing.duration",
+		metric.WithDescription("Time spent loading the key and signing the token of a call, across attempts."),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, call *wallet.Call, next wallet.Next) error {
+		ctx, span := tracer.Start(ctx, "wallet "+call.Name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(APINameKey.String(call.Name), CallKindKey.String(string(call.Kind))),
+		)
+		defer span.End()
+		if call.Header == nil {
+			call.Header = http.Header{}
+		}
+		o.Propagator.Inject(ctx, propagation.HeaderCarrier(call.Header))
+
+		start := time.Now()
+		err := next(ctx, call)
+		elapsed := time.Since(start)
+
+		attrs := []attribute.KeyValue{APINameKey.String(call.Name), CallKindKey.String(string(call.Kind))}
+		if call.StatusCode != 0 {
+			attrs = append(attrs, StatusCodeKey.Int(call.StatusCode))
+		}
+		if err != nil {
+			attrs = append(attrs, ErrorCodeKey.String(errorCode(err)))
+		}
+		span.SetAttributes(attrs...)
+		span.SetAttributes(
+			AttemptsKey.Int(call.Attempt),
+			RateLimitWaitKey.Float64(call.RateLimitWait.Seconds()),
+			RateLimitedAttemptsKey.Int(call.RateLimitedAttempts),
+		)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		set := metric.WithAttributeSet(attribute.NewSet(attrs...))
+		m.duration.Record(ctx, elapsed.Seconds(), set)
+		if err != nil {
+			m.errors.Add(ctx, 1, set)
+		}
+		if call.RateLimitedAttempts > 0 {
+			m.rateLimited.Add(ctx, int64(call.RateLimitedAttempts), metric.WithAttributes(APINameKey.String(call.Name)))
+		}
+		if call.SigningDuration > 0 {
+			m.signing.Record(ctx, call.SigningDuration.Seconds(), metric.WithAttributes(APINameKey.String(call.Name)))
+		}
+		return err
+	}, nil
+}
+
+// errorCode returns the value of [ErrorCodeKey] for err.
+func errorCode(err error) string {
+	var werr wallet.Error
+	switch {
+	case errors.As(err, &werr) && werr.Code != "":
+		return werr.Code
+	case errors.Is(err, wallet.ErrTransport):
+		return "transport"
+	case errors.Is(err, wallet.ErrDecode):
+		return "decode"
+	}
+	return "other"
+}
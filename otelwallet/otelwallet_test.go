@@ -0,0 +1,123 @@
+package otelwallet_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/otelwallet"
+	"github.com/halogencapital/wallet-go/wallettest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func attributeOf(attrs []attribute.KeyValue, key attribute.Key) attribute.Value {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func metricOf(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.Aggregation {
+	t.Helper()
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m.Data
+			}
+		}
+	}
+	t.Fatalf("metric %s was not recorded", name)
+	return nil
+}
+
+func TestInterceptor(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	exporter := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	interceptor, err := otelwallet.NewInterceptor(&otelwallet.Options{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := srv.NewClient(&wallet.Options{
+		Interceptors: []wallet.Interceptor{interceptor},
+		RetryPolicy:  &wallet.RetryPolicy{InitialBackoff: time.Millisecond, Jitter: -1},
+	})
+	ctx := context.Background()
+
+	srv.RateLimit("list_banks", time.Millisecond, 1)
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); err != nil {
+		t.Fatal(err)
+	}
+	srv.FailNext("get_fund", wallet.ErrMissingResource)
+	if _, err := c.GetFund(ctx, &wallet.GetFundInput{FundID: wallettest.BitcoinFundID}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	banks, fund := spans[0], spans[1]
+	if banks.Name != "wallet list_banks" || attributeOf(banks.Attributes, otelwallet.AttemptsKey).AsInt64() != 2 ||
+		attributeOf(banks.Attributes, otelwallet.RateLimitedAttemptsKey).AsInt64() != 1 ||
+		attributeOf(banks.Attributes, otelwallet.StatusCodeKey).AsInt64() != 200 || banks.Status.Code == codes.Error {
+		t.Fatalf("unexpected span %s %v", banks.Name, banks.Attributes)
+	}
+	if fund.Status.Code != codes.Error || attributeOf(fund.Attributes, otelwallet.ErrorCodeKey).AsString() != wallet.ErrMissingResource {
+		t.Fatalf("unexpected span %s %v", fund.Name, fund.Attributes)
+	}
+
+	calls := srv.Calls()
+	if traceparent := calls[len(calls)-1].Header.Get("Traceparent"); traceparent == "" || traceparent[3:35] != fund.SpanContext.TraceID().String() {
+		t.Fatalf("expected the trace context to be propagated, got %q", traceparent)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	if h := metricOf(t, rm, "wallet.client.duration").(metricdata.Histogram[float64]); len(h.DataPoints) != 2 {
+		t.Fatalf("got %d duration series, want 2", len(h.DataPoints))
+	}
+	errors := metricOf(t, rm, "wallet.client.errors").(metricdata.Sum[int64])
+	if len(errors.DataPoints) != 1 || errors.DataPoints[0].Value != 1 {
+		t.Fatalf("unexpected errors %+v", errors.DataPoints)
+	}
+	if code, _ := errors.DataPoints[0].Attributes.Value(otelwallet.ErrorCodeKey); code.AsString() != wallet.ErrMissingResource {
+		t.Fatalf("got error code %q, want %s", code.AsString(), wallet.ErrMissingResource)
+	}
+	if s := metricOf(t, rm, "wallet.client.rate_limited").(metricdata.Sum[int64]); len(s.DataPoints) != 1 || s.DataPoints[0].Value != 1 {
+		t.Fatalf("unexpected rate limited %+v", s.DataPoints)
+	}
+	if h := metricOf(t, rm, "wallet.client.signing.duration").(metricdata.Histogram[float64]); len(h.DataPoints) != 2 || h.DataPoints[0].Count == 0 {
+		t.Fatalf("unexpected signing durations %+v", h.DataPoints)
+	}
+}
+
+func TestInterceptorWithoutProviders(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	interceptor, err := otelwallet.NewInterceptor(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := srv.NewClient(&wallet.Options{Interceptors: []wallet.Interceptor{interceptor}})
+	if _, err := c.ListBanks(context.Background(), &wallet.ListBanksInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if traceparent := srv.Calls()[0].Header.Get("Traceparent"); traceparent != "" {
+		t.Fatalf("expected no trace context without a span, got %q", traceparent)
+	}
+}
@@ -0,0 +1,102 @@
+package wallet
+
+import (
+	"context"
+	"iter"
+)
+
+// DefaultPageSize is the number of requests fetched per call by [Client.AllClientAccountRequests]
+// when the input has no Limit.
+const DefaultPageSize = 100
+
+// AllClientAccountRequests returns an iterator over the requests matching input, newest first,
+// fetching them page by page with [Client.ListClientAccountRequests]:
+//
+//	for r, err := range client.AllClientAccountRequests(ctx, input) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(r.ID, r.Status)
+//	}
+//
+// input.Limit is the page size, [DefaultPageSize] when nil, and input.Offset is where the scan
+// starts. The other fields filter the requests as with ListClientAccountRequests. input is not
+// modified.
+//
+// The scan ends with the first page adding no request, either empty or holding requests already
+// returned, so it costs one more query than there are pages.
+// Requests created while iterating shift the following pages: a request seen at the end of a
+// page may be returned again at the start of the next one, so requests are deduplicated by ID.
+//
+// Each page is a query going through the interceptors of the client, so it waits on
+// [Options.RateLimiter] and is retried following [Options.RetryPolicy]. Iteration stops at the
+// first error, which is yielded with a zero request, including when ctx is done.
+func (c *Client) AllClientAccountRequests(ctx context.Context, input *ListClientAccountRequestsInput) iter.Seq2[ClientAccountRequest, error] {
+	return func(yield func(ClientAccountRequest, error) bool) {
+		page := ListClientAccountRequestsInput{}
+		if input != nil {
+			page = *input
+		}
+		pageSize, offset := DefaultPageSize, 0
+		if page.Limit != nil {
+			pageSize = *page.Limit
+		}
+		if page.Offset != nil {
+			offset = *page.Offset
+		}
+		if pageSize <= 0 {
+			detail := ErrorDetail{Field: "limit", Code: ErrInvalidParameter, Message: "limit must be positive to paginate"}
+			yield(ClientAccountRequest{}, Error{
+				Code:    detail.Code,
+				Message: detail.Message,
+				Details: []ErrorDetail{detail},
+				APIName: "list_client_account_requests",
+			})
+			return
+		}
+		seen := map[string]bool{}
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(ClientAccountRequest{}, err)
+				return
+			}
+			page.Limit, page.Offset = &pageSize, &offset
+			output, err := c.ListClientAccountRequests(ctx, &page)
+			if err != nil {
+				yield(ClientAccountRequest{}, err)
+				return
+			}
+			added := false
+			for _, r := range output.Requests {
+				if seen[r.ID] {
+					continue
+				}
+				seen[r.ID] = true
+				added = true
+				if !yield(r, nil) {
+					return
+				}
+			}
+			// the server may return fewer requests than the page size, for instance when it
+			// caps Limit, so the scan only ends with a page adding no request: an empty one,
+			// or one repeating requests already seen when the server ignores Offset.
+			if !added {
+				return
+			}
+			offset += len(output.Requests)
+		}
+	}
+}
+
+// CollectClientAccountRequests returns all the requests matching input, see
+// [Client.AllClientAccountRequests]. On error, it returns the requests collected so far.
+func (c *Client) CollectClientAccountRequests(ctx context.Context, input *ListClientAccountRequestsInput) ([]ClientAccountRequest, error) {
+	var requests []ClientAccountRequest
+	for r, err := range c.AllClientAccountRequests(ctx, input) {
+		if err != nil {
+			return requests, err
+		}
+		requests = append(requests, r)
+	}
+	return requests, nil
+}
@@ -0,0 +1,183 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+// seedRequests replaces the requests of the single account with n completed investments,
+// created one minute apart.
+func seedRequests(srv *wallettest.Server, n int) {
+	start := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	srv.State(func(seed *wallettest.Seed) {
+		requests := make([]wallet.ClientAccountRequest, n)
+		for i := range requests {
+			requests[i] = wallet.ClientAccountRequest{
+				ID:        fmt.Sprintf("req-%03d", i),
+				Type:      wallet.RequestTypeInvestment,
+				Status:    wallet.RequestStatusCompleted,
+				CreatedAt: wallet.Timestamp{Time: start.Add(time.Duration(i) * time.Minute)},
+			}
+		}
+		seed.Requests[wallettest.SingleAccountID] = requests
+	})
+}
+
+func TestAllClientAccountRequests(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	seedRequests(srv, 25)
+
+	limit := 10
+	input := &wallet.ListClientAccountRequestsInput{AccountID: wallettest.SingleAccountID, Limit: &limit}
+	var ids []string
+	for r, err := range c.AllClientAccountRequests(context.Background(), input) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) == 0 {
+			// a request created mid-scan shifts the next pages by one
+			srv.State(func(seed *wallettest.Seed) {
+				seed.Requests[wallettest.SingleAccountID] = append(seed.Requests[wallettest.SingleAccountID], wallet.ClientAccountRequest{
+					ID:        "req-new",
+					Status:    wallet.RequestStatusPending,
+					CreatedAt: wallet.Timestamp{Time: time.Now()},
+				})
+			})
+		}
+		ids = append(ids, r.ID)
+	}
+	if len(ids) != 25 {
+		t.Fatalf("got %d requests, want 25", len(ids))
+	}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			t.Fatalf("request %s returned twice", id)
+		}
+		seen[id] = true
+	}
+	if ids[0] != "req-024" || ids[24] != "req-000" {
+		t.Fatalf("got %s to %s, want newest first", ids[0], ids[24])
+	}
+	if calls := len(srv.Calls()); calls != 4 {
+		t.Fatalf("got %d calls, want 3 pages and an empty one", calls)
+	}
+	if *input.Limit != 10 || input.Offset != nil {
+		t.Fatal("expected the input not to be modified")
+	}
+}
+
+func TestCollectClientAccountRequests(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	seedRequests(srv, 5)
+	ctx := context.Background()
+
+	from := wallet.NewDate(2025, time.March, 1)
+	requests, err := c.CollectClientAccountRequests(ctx, &wallet.ListClientAccountRequestsInput{
+		AccountID: wallettest.SingleAccountID,
+		FromDate:  &from,
+		ToDate:    &from,
+		Statuses:  []wallet.RequestStatus{wallet.RequestStatusCompleted},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 5 {
+		t.Fatalf("got %d requests, want 5", len(requests))
+	}
+
+	zero := 0
+	_, err = c.CollectClientAccountRequests(ctx, &wallet.ListClientAccountRequestsInput{AccountID: wallettest.SingleAccountID, Limit: &zero})
+	if !errors.Is(err, wallet.Error{Code: wallet.ErrInvalidParameter}) {
+		t.Fatalf("got %v, want %s", err, wallet.ErrInvalidParameter)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	requests, err = c.CollectClientAccountRequests(ctx, &wallet.ListClientAccountRequestsInput{AccountID: wallettest.SingleAccountID})
+	if !errors.Is(err, context.Canceled) || len(requests) != 0 {
+		t.Fatalf("got %d requests and %v, want %v", len(requests), err, context.Canceled)
+	}
+}
+
+func TestAllClientAccountRequestsCappedLimit(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	// the server returns at most 4 requests per page, whatever the requested limit.
+	c := srv.NewClient(&wallet.Options{Interceptors: []wallet.Interceptor{
+		func(ctx context.Context, call *wallet.Call, next wallet.Next) error {
+			if input, ok := call.Input.(*wallet.ListClientAccountRequestsInput); ok && input.Limit != nil && *input.Limit > 4 {
+				capped := 4
+				input.Limit = &capped
+			}
+			return next(ctx, call)
+		},
+	}})
+	seedRequests(srv, 10)
+
+	requests, err := c.CollectClientAccountRequests(context.Background(), &wallet.ListClientAccountRequestsInput{AccountID: wallettest.SingleAccountID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 10 {
+		t.Fatalf("got %d requests, want 10", len(requests))
+	}
+}
+
+func TestAllClientAccountRequestsIgnoredOffset(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	// the server always returns the first page, whatever the requested offset.
+	c := srv.NewClient(&wallet.Options{Interceptors: []wallet.Interceptor{
+		func(ctx context.Context, call *wallet.Call, next wallet.Next) error {
+			if input, ok := call.Input.(*wallet.ListClientAccountRequestsInput); ok {
+				input.Offset = nil
+			}
+			return next(ctx, call)
+		},
+	}})
+	seedRequests(srv, 10)
+
+	limit := 4
+	requests, err := c.CollectClientAccountRequests(context.Background(), &wallet.ListClientAccountRequestsInput{AccountID: wallettest.SingleAccountID, Limit: &limit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 4 {
+		t.Fatalf("got %d requests, want the 4 of the first page", len(requests))
+	}
+	if calls := len(srv.Calls()); calls != 2 {
+		t.Fatalf("got %d calls, want the scan to stop on the repeated page", calls)
+	}
+}
+
+func TestAllClientAccountRequestsBreak(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	seedRequests(srv, 25)
+
+	limit := 10
+	n := 0
+	for _, err := range c.AllClientAccountRequests(context.Background(), &wallet.ListClientAccountRequestsInput{AccountID: wallettest.SingleAccountID, Limit: &limit}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; n == 3 {
+			break
+		}
+	}
+	if calls := len(srv.Calls()); calls != 1 {
+		t.Fatalf("got %d calls, want a single page fetched", calls)
+	}
+}
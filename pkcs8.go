@@ -0,0 +1,292 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// Object identifiers of the algorithms of encrypted PKCS #8 private keys, see RFC 8018.
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidScrypt         = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11591, 4, 11}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidAES128GCM      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 6}
+	oidAES192GCM      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 26}
+	oidAES256GCM      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 46}
+)
+
+// ErrIncorrectPassphrase is returned when an encrypted private key cannot be decrypted with
+// the passphrase returned by [Options.PassphraseFunc].
+var ErrIncorrectPassphrase = errors.New("wallet: incorrect passphrase")
+
+// pbkdf2Iterations is the PBKDF2-HMAC-SHA256 iteration count of the keys encrypted by
+// [GenerateKeyPair], as recommended by OWASP.
+const pbkdf2Iterations = 600_000
+
+// pbkdf2MaxIterations caps the PBKDF2 iteration count of the keys decrypted by pbes2Key, so
+// that a crafted private key cannot make the client spin for minutes.
+const pbkdf2MaxIterations = 10_000_000
+
+// encryptedPrivateKeyInfo is the EncryptedPrivateKeyInfo structure of RFC 5958.
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// pbes2Params is the PBES2-params structure of RFC 8018.
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params is the PBKDF2-params structure of RFC 8018.
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// scryptParams is the scrypt-params structure of RFC 7914.
+type scryptParams struct {
+	Salt                     []byte
+	CostParameter            int
+	BlockSize                int
+	ParallelizationParameter int
+	KeyLength                int `asn1:"optional"`
+}
+
+// gcmParams is the GCMParameters structure of RFC 5084.
+type gcmParams struct {
+	Nonce  []byte
+	ICVLen int `asn1:"default:12"`
+}
+
+// encryptPKCS8PrivateKey encrypts the PKCS #8 private key der with passphrase, using PBES2
+// with PBKDF2-HMAC-SHA256 and AES-256-CBC, the default of OpenSSL 3. It returns an
+// "ENCRYPTED PRIVATE KEY" PEM block.
+func encryptPKCS8PrivateKey(der []byte, passphrase []byte) (*pem.Block, error) {
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	key, err := pbkdf2.Key(sha256.New, string(passphrase), salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// PKCS #7 padding, always at least one byte.
+	padding := aes.BlockSize - len(der)%aes.BlockSize
+	plaintext := make([]byte, len(der)+padding)
+	defer clear(plaintext)
+	copy(plaintext, der)
+	for i := len(der); i < len(plaintext); i++ {
+		plaintext[i] = byte(padding)
+	}
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plaintext)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: pbkdf2Iterations,
+		PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParams}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	b, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: ciphertext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wallet: unable to encode the encrypted private key. err=%v", err)
+	}
+	return &pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: b}, nil
+}
+
+// decryptPKCS8PrivateKey decrypts the DER bytes of an "ENCRYPTED PRIVATE KEY" PEM block into
+// a PKCS #8 private key, which the caller clears after use. It supports PBES2 with PBKDF2 or
+// scrypt, and AES in CBC or GCM mode.
+func decryptPKCS8PrivateKey(der []byte, passphrase []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("wallet: encrypted private key cannot be parsed.")
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("wallet: unsupported private key encryption %s. Valid encryption would be PBES2.", info.Algorithm.Algorithm)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("wallet: PBES2 parameters cannot be parsed.")
+	}
+
+	keyLen, newAEAD, err := pbes2Cipher(params.EncryptionScheme)
+	if err != nil {
+		return nil, err
+	}
+	key, err := pbes2Key(params.KeyDerivationFunc, passphrase, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if newAEAD != nil {
+		return newAEAD(block, info.EncryptedData)
+	}
+
+	// CBC, with the IV as parameters and PKCS #7 padding.
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("wallet: AES-CBC parameters cannot be parsed.")
+	}
+	if len(info.EncryptedData) == 0 || len(info.EncryptedData)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("wallet: encrypted private key has an invalid length.")
+	}
+	plaintext := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, info.EncryptedData)
+	padding := int(plaintext[len(plaintext)-1])
+	valid := padding > 0 && padding <= aes.BlockSize
+	for i := 0; valid && i < padding; i++ {
+		valid = plaintext[len(plaintext)-1-i] == byte(padding)
+	}
+	if !valid {
+		clear(plaintext)
+		return nil, ErrIncorrectPassphrase
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
+
+// pbes2Key derives the encryption key of PBES2 from passphrase with kdf, either PBKDF2 or scrypt.
+func pbes2Key(kdf pkix.AlgorithmIdentifier, passphrase []byte, keyLen int) ([]byte, error) {
+	switch {
+	case kdf.Algorithm.Equal(oidPBKDF2):
+		var params pbkdf2Params
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &params); err != nil {
+			return nil, fmt.Errorf("wallet: PBKDF2 parameters cannot be parsed.")
+		}
+		if params.KeyLength != 0 && params.KeyLength != keyLen {
+			return nil, fmt.Errorf("wallet: PBKDF2 key length %d does not match the cipher.", params.KeyLength)
+		}
+		if params.IterationCount < 1 || params.IterationCount > pbkdf2MaxIterations {
+			return nil, fmt.Errorf("wallet: PBKDF2 iteration count %d is not between 1 and %d.", params.IterationCount, pbkdf2MaxIterations)
+		}
+		var h func() hash.Hash
+		switch prf := params.PRF.Algorithm; {
+		case len(prf) == 0, prf.Equal(oidHMACWithSHA1):
+			h = sha1.New
+		case prf.Equal(oidHMACWithSHA224):
+			h = sha256.New224
+		case prf.Equal(oidHMACWithSHA256):
+			h = sha256.New
+		case prf.Equal(oidHMACWithSHA384):
+			h = sha512.New384
+		case prf.Equal(oidHMACWithSHA512):
+			h = sha512.New
+		default:
+			return nil, fmt.Errorf("wallet: unsupported PBKDF2 pseudorandom function %s.", prf)
+		}
+		return pbkdf2.Key(h, string(passphrase), params.Salt, params.IterationCount, keyLen)
+	case kdf.Algorithm.Equal(oidScrypt):
+		var params scryptParams
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &params); err != nil {
+			return nil, fmt.Errorf("wallet: scrypt parameters cannot be parsed.")
+		}
+		if params.KeyLength != 0 && params.KeyLength != keyLen {
+			return nil, fmt.Errorf("wallet: scrypt key length %d does not match the cipher.", params.KeyLength)
+		}
+		return scryptKey(passphrase, params.Salt, params.CostParameter, params.BlockSize, params.ParallelizationParameter, keyLen)
+	default:
+		return nil, fmt.Errorf("wallet: unsupported key derivation function %s. Valid function would either be PBKDF2 or scrypt.", kdf.Algorithm)
+	}
+}
+
+// pbes2Cipher returns the key length of the encryption scheme of PBES2, and for AES-GCM a
+// function decrypting with it. The function is nil for AES-CBC.
+func pbes2Cipher(scheme pkix.AlgorithmIdentifier) (int, func(block cipher.Block, ciphertext []byte) ([]byte, error), error) {
+	switch oid := scheme.Algorithm; {
+	case oid.Equal(oidAES128CBC):
+		return 16, nil, nil
+	case oid.Equal(oidAES192CBC):
+		return 24, nil, nil
+	case oid.Equal(oidAES256CBC):
+		return 32, nil, nil
+	}
+
+	var keyLen int
+	switch oid := scheme.Algorithm; {
+	case oid.Equal(oidAES128GCM):
+		keyLen = 16
+	case oid.Equal(oidAES192GCM):
+		keyLen = 24
+	case oid.Equal(oidAES256GCM):
+		keyLen = 32
+	default:
+		return 0, nil, fmt.Errorf("wallet: unsupported private key cipher %s. Valid cipher would either be AES-CBC or AES-GCM.", oid)
+	}
+	var params gcmParams
+	if _, err := asn1.Unmarshal(scheme.Parameters.FullBytes, &params); err != nil {
+		return 0, nil, fmt.Errorf("wallet: AES-GCM parameters cannot be parsed.")
+	}
+	return keyLen, func(block cipher.Block, ciphertext []byte) ([]byte, error) {
+		var aead cipher.AEAD
+		var err error
+		switch {
+		case len(params.Nonce) == 12:
+			aead, err = cipher.NewGCMWithTagSize(block, params.ICVLen)
+		case params.ICVLen == 16:
+			aead, err = cipher.NewGCMWithNonceSize(block, len(params.Nonce))
+		default:
+			err = fmt.Errorf("wallet: unsupported AES-GCM nonce size %d with tag size %d.", len(params.Nonce), params.ICVLen)
+		}
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := aead.Open(nil, params.Nonce, ciphertext, nil)
+		if err != nil {
+			return nil, ErrIncorrectPassphrase
+		}
+		return plaintext, nil
+	}, nil
+}
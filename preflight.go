@@ -0,0 +1,307 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ValidationReport lists the rules a command would break if it were sent, as found by
+// [Client.ValidateInvestment], [Client.ValidateRedemption] and [Client.ValidateSwitch].
+type ValidationReport struct {
+	// APIName is the name of the validated command, for instance "create_investment_request".
+	APIName string
+
+	// Violations lists the broken rules in the order the API checks them. Each Code is one of
+	// the error codes the command would fail with, such as [ErrInsufficientBalance], and Field
+	// names the input field at fault, when there is one.
+	Violations []ErrorDetail
+}
+
+// OK reports whether no rule is broken.
+func (r *ValidationReport) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// Has reports whether a rule with the given code is broken.
+func (r *ValidationReport) Has(code string) bool {
+	for _, v := range r.Violations {
+		if v.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns nil when the report is OK, otherwise an [Error] with the code and message of the
+// first violation and all violations in Details, as the API would have returned it.
+func (r *ValidationReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	return Error{
+		Code:    r.Violations[0].Code,
+		Message: r.Violations[0].Message,
+		Details: r.Violations,
+		APIName: r.APIName,
+	}
+}
+
+func (r *ValidationReport) add(code string, field string, format string, args ...any) {
+	r.Violations = append(r.Violations, ErrorDetail{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// ValidateInvestment checks input against the account, the fund class, the current balance, the
+// required consents and the suitability assessment of the client, without creating the request:
+//
+//	report, err := client.ValidateInvestment(ctx, input)
+//	if err != nil {
+//		return err // the data could not be fetched
+//	}
+//	if !report.OK() {
+//		return report.Err()
+//	}
+//	output, err := client.CreateInvestmentRequest(ctx, input)
+//
+// The checks only use queries, so they are subject to the same rate limit as other calls. The
+// report reflects the state at the time of the check: the command may still be rejected, for
+// instance when the fund goes out of service in between. The returned error is only set when
+// the data needed by the checks could not be fetched.
+func (c *Client) ValidateInvestment(ctx context.Context, input *CreateInvestmentRequestInput) (*ValidationReport, error) {
+	if input == nil {
+		input = &CreateInvestmentRequestInput{}
+	}
+	r := &ValidationReport{APIName: "create_investment_request"}
+	account, err := c.preflightAccount(ctx, r, input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil && !account.CanInvest {
+		r.add(ErrActionNotAllowedForAccountType, "accountId", "account %q cannot invest", account.ID)
+	}
+	fund, class, err := c.preflightFundClass(ctx, r, "fundId", input.FundID, input.FundClassSequence)
+	if err != nil {
+		return nil, err
+	}
+	if fund != nil && fund.IsOutOfService {
+		r.add(ErrActionOutsideFundHours, "fundId", "fund %q is out of service: %s", fund.ID, fund.OutOfServiceMessage)
+	}
+
+	if input.Amount.Sign() <= 0 {
+		r.add(ErrMissingParameter, "amount", "amount is required")
+	} else if class != nil {
+		minimum, label := class.MinimumInitialInvestment, "initial"
+		if account != nil {
+			b, err := c.preflightBalance(ctx, account.ID, fund.ID, class.Sequence)
+			if err != nil {
+				return nil, err
+			}
+			if b != nil {
+				minimum, label = class.MinimumAdditionalInvestment, "additional"
+			}
+		}
+		if input.Amount.Cmp(minimum) < 0 {
+			r.add(ErrInvalidParameter, "amount", "amount must be at least %v, the minimum %s investment", minimum, label)
+		}
+	}
+
+	if account != nil && class != nil {
+		consents, err := c.ListInvestConsents(ctx, &ListInvestConsentsInput{AccountID: account.ID, FundID: fund.ID, FundClassSequence: class.Sequence})
+		if err != nil {
+			return nil, err
+		}
+		for _, consent := range consents.Consents {
+			if !input.Consents[consent.Name] {
+				r.add(ErrMissingParameter, "consents", "consent %q is required", consent.Name)
+			}
+		}
+	}
+
+	suitability, err := c.ListClientSuitabilityAssessments(ctx, &ListClientSuitabilityAssessmentsInput{})
+	if err != nil {
+		return nil, err
+	}
+	if suitability.ShouldAskSuitabilityAssessment && !suitability.CanIgnoreSuitabilityAssessment {
+		r.add(ErrSuitabilityAssessmentRequired, "", "a suitability assessment must be completed before investing")
+	}
+
+	if input.VoucherCode != "" && account != nil && class != nil && input.Amount.Sign() > 0 {
+		voucher, err := c.GetVoucher(ctx, &GetVoucherInput{
+			AccountID:         account.ID,
+			FundID:            fund.ID,
+			FundClassSequence: class.Sequence,
+			Amount:            input.Amount,
+			VoucherCode:       &input.VoucherCode,
+		})
+		if err != nil && !errors.Is(err, Error{Code: ErrMissingResource}) {
+			return nil, err
+		}
+		if err != nil || !voucher.Valid {
+			r.add(ErrInvalidParameter, "voucherCode", "voucher %q is not valid", input.VoucherCode)
+		}
+	}
+	return r, nil
+}
+
+// ValidateRedemption checks input against the account, the destination bank account and the
+// holding of the fund class, without creating the request. See [Client.ValidateInvestment].
+func (c *Client) ValidateRedemption(ctx context.Context, input *CreateRedemptionRequestInput) (*ValidationReport, error) {
+	if input == nil {
+		input = &CreateRedemptionRequestInput{}
+	}
+	r := &ValidationReport{APIName: "create_redemption_request"}
+	account, err := c.preflightAccount(ctx, r, input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil && !account.CanRedeem {
+		r.add(ErrActionNotAllowedForAccountType, "accountId", "account %q cannot redeem", account.ID)
+	}
+	if input.ToBankAccountNumber != "" {
+		output, err := c.ListClientBankAccounts(ctx, &ListClientBankAccountsInput{})
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, b := range output.BankAccounts {
+			found = found || b.AccountNumber == input.ToBankAccountNumber
+		}
+		if !found {
+			r.add(ErrMissingResource, "toBankAccountNumber", "bank account %q does not exist", input.ToBankAccountNumber)
+		}
+	}
+	if err := c.preflightHolding(ctx, r, account, "fundId", input.FundID, input.FundClassSequence, input.RequestedAmount, input.Units); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ValidateSwitch checks input against the account, the target fund class and the holding of the
+// source fund class, without creating the request. See [Client.ValidateInvestment].
+func (c *Client) ValidateSwitch(ctx context.Context, input *CreateSwitchRequestInput) (*ValidationReport, error) {
+	if input == nil {
+		input = &CreateSwitchRequestInput{}
+	}
+	r := &ValidationReport{APIName: "create_switch_request"}
+	account, err := c.preflightAccount(ctx, r, input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil && !account.CanSwitch {
+		r.add(ErrActionNotAllowedForAccountType, "accountId", "account %q cannot switch", account.ID)
+	}
+	toFund, _, err := c.preflightFundClass(ctx, r, "switchToFundId", input.SwitchToFundID, input.SwitchToFundClassSequence)
+	if err != nil {
+		return nil, err
+	}
+	if toFund != nil && toFund.IsOutOfService {
+		r.add(ErrActionOutsideFundHours, "switchToFundId", "fund %q is out of service: %s", toFund.ID, toFund.OutOfServiceMessage)
+	}
+	if err := c.preflightHolding(ctx, r, account, "switchFromFundId", input.SwitchFromFundID, input.SwitchFromFundClassSequence, input.RequestedAmount, input.Units); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// preflightAccount returns the account, or nil when it is missing or not accessible, in which
+// case a violation is added to r.
+func (c *Client) preflightAccount(ctx context.Context, r *ValidationReport, accountID string) (*ClientAccount, error) {
+	if accountID == "" {
+		r.add(ErrMissingParameter, "accountId", "accountId is required")
+		return nil, nil
+	}
+	output, err := c.ListClientAccounts(ctx, &ListClientAccountsInput{AccountIDs: []string{accountID}})
+	if err != nil && !errors.Is(err, Error{Code: ErrInsufficientAccess}) {
+		return nil, err
+	}
+	if err == nil {
+		for i := range output.Accounts {
+			if output.Accounts[i].ID == accountID {
+				return &output.Accounts[i], nil
+			}
+		}
+	}
+	r.add(ErrInsufficientAccess, "accountId", "account %q is not accessible", accountID)
+	return nil, nil
+}
+
+// preflightFundClass returns the fund and its class, or nils when either does not exist, in
+// which case a violation on field is added to r.
+func (c *Client) preflightFundClass(ctx context.Context, r *ValidationReport, field string, fundID string, sequence int) (*Fund, *FundClass, error) {
+	if fundID == "" {
+		r.add(ErrMissingParameter, field, "%s is required", field)
+		return nil, nil, nil
+	}
+	output, err := c.GetFund(ctx, &GetFundInput{FundID: fundID})
+	if errors.Is(err, Error{Code: ErrMissingResource}) {
+		r.add(ErrMissingResource, field, "fund %q does not exist", fundID)
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if output.Fund == nil {
+		r.add(ErrMissingResource, field, "fund %q does not exist", fundID)
+		return nil, nil, nil
+	}
+	for i := range output.Fund.Classes {
+		if output.Fund.Classes[i].Sequence == sequence {
+			return output.Fund, &output.Fund.Classes[i], nil
+		}
+	}
+	r.add(ErrMissingResource, field, "fund %q has no class %d", fundID, sequence)
+	return nil, nil, nil
+}
+
+// preflightBalance returns the balance the account holds in the fund class, nil if none.
+func (c *Client) preflightBalance(ctx context.Context, accountID string, fundID string, sequence int) (*Balance, error) {
+	output, err := c.ListClientAccountBalance(ctx, &ListClientAccountBalanceInput{AccountID: accountID})
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range output.Balance {
+		if b != nil && b.FundID == fundID && b.FundClassSequence == sequence {
+			return b, nil
+		}
+	}
+	return nil, nil
+}
+
+// preflightHolding checks that the account holds enough of the fund class to take out amount or
+// units, following the rules the API applies to redemptions and switches.
+func (c *Client) preflightHolding(ctx context.Context, r *ValidationReport, account *ClientAccount, field string, fundID string, sequence int, amount Decimal, units Decimal) error {
+	if amount.Sign() <= 0 && units.Sign() <= 0 {
+		r.add(ErrMissingParameter, "requestedAmount", "either requestedAmount or units is required")
+	}
+	if fundID == "" {
+		r.add(ErrMissingParameter, field, "%s is required", field)
+		return nil
+	}
+	if account == nil {
+		return nil
+	}
+	b, err := c.preflightBalance(ctx, account.ID, fundID, sequence)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		r.add(ErrInsufficientBalance, field, "account does not hold fund %q class %d", fundID, sequence)
+		return nil
+	}
+	if b.IsOutOfService {
+		r.add(ErrActionOutsideFundHours, field, "fund %q is out of service: %s", fundID, b.OutOfServiceMessage)
+	}
+	if units.Cmp(b.Units) > 0 {
+		r.add(ErrInsufficientBalance, "units", "units exceed the %v units held", b.Units)
+	}
+	if amount.Cmp(b.Value) > 0 {
+		r.add(ErrInsufficientBalance, "requestedAmount", "requestedAmount exceeds the %v %s held", b.Value, b.Asset)
+	}
+	if amount.Sign() > 0 && amount.Cmp(b.MinimumRedemptionAmount) < 0 {
+		r.add(ErrInvalidParameter, "requestedAmount", "requestedAmount must be at least %v", b.MinimumRedemptionAmount)
+	}
+	if units.Sign() > 0 && units.Cmp(b.MinimumRedemptionUnits) < 0 {
+		r.add(ErrInvalidParameter, "units", "units must be at least %v", b.MinimumRedemptionUnits)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func violationCodes(r *wallet.ValidationReport) []string {
+	var codes []string
+	for _, v := range r.Violations {
+		codes = append(codes, v.Code)
+	}
+	return codes
+}
+
+func TestValidateInvestment(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	ctx := context.Background()
+
+	input := &wallet.CreateInvestmentRequestInput{
+		AccountID:         wallettest.SingleAccountID,
+		FundID:            wallettest.BitcoinFundID,
+		FundClassSequence: 1,
+		Amount:            wallet.MustParseDecimal("500"),
+		Consents:          map[string]bool{"IM": true, "highRisk": true},
+	}
+	report, err := c.ValidateInvestment(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() || report.Err() != nil {
+		t.Fatalf("unexpected violations %+v", report.Violations)
+	}
+	if _, err := c.CreateInvestmentRequest(ctx, input); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.State(func(seed *wallettest.Seed) {
+		seed.SuitabilityAssessments.ShouldAskSuitabilityAssessment = true
+	})
+	input = &wallet.CreateInvestmentRequestInput{
+		AccountID:         wallettest.JointAccountID,
+		FundID:            wallettest.BitcoinFundID,
+		FundClassSequence: 1,
+		Amount:            wallet.MustParseDecimal("500"),
+		Consents:          map[string]bool{"IM": true},
+		VoucherCode:       "UNKNOWN",
+	}
+	report, err = c.ValidateInvestment(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{wallet.ErrInvalidParameter, wallet.ErrMissingParameter, wallet.ErrSuitabilityAssessmentRequired, wallet.ErrInvalidParameter}
+	if got := violationCodes(report); !slices.Equal(got, want) {
+		t.Fatalf("got violations %v, want %v", got, want)
+	}
+	if report.Violations[0].Field != "amount" || report.Violations[3].Field != "voucherCode" {
+		t.Fatalf("unexpected fields %+v", report.Violations)
+	}
+	if !report.Has(wallet.ErrSuitabilityAssessmentRequired) || report.Has(wallet.ErrInsufficientBalance) {
+		t.Fatal("unexpected Has result")
+	}
+
+	// the report fails with the same code as the command would
+	_, cmdErr := c.CreateInvestmentRequest(ctx, input)
+	var werr wallet.Error
+	if !errors.As(report.Err(), &werr) || len(werr.Details) != 4 || !errors.Is(cmdErr, wallet.Error{Code: werr.Code}) {
+		t.Fatalf("got %v from the report and %v from the command", report.Err(), cmdErr)
+	}
+}
+
+func TestValidateRedemption(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	ctx := context.Background()
+
+	input := &wallet.CreateRedemptionRequestInput{
+		AccountID:         wallettest.SingleAccountID,
+		FundID:            wallettest.BitcoinFundID,
+		FundClassSequence: 1,
+		Units:             wallet.MustParseDecimal("9000"),
+	}
+	report, err := c.ValidateRedemption(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := violationCodes(report); !slices.Equal(got, []string{wallet.ErrInsufficientBalance}) {
+		t.Fatalf("got violations %v, want %s", got, wallet.ErrInsufficientBalance)
+	}
+
+	input.Units = wallet.MustParseDecimal("10")
+	input.ToBankAccountNumber = "0000000000"
+	report, err = c.ValidateRedemption(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := violationCodes(report); !slices.Equal(got, []string{wallet.ErrMissingResource, wallet.ErrInvalidParameter}) {
+		t.Fatalf("unexpected violations %+v", report.Violations)
+	}
+
+	srv.State(func(seed *wallettest.Seed) {
+		seed.Accounts[0].CanRedeem = false
+	})
+	input.Units, input.ToBankAccountNumber = wallet.MustParseDecimal("100"), ""
+	report, err = c.ValidateRedemption(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := violationCodes(report); !slices.Equal(got, []string{wallet.ErrActionNotAllowedForAccountType}) {
+		t.Fatalf("unexpected violations %+v", report.Violations)
+	}
+}
+
+func TestValidateSwitch(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	ctx := context.Background()
+
+	input := &wallet.CreateSwitchRequestInput{
+		AccountID:                   wallettest.SingleAccountID,
+		SwitchFromFundID:            wallettest.BitcoinFundID,
+		SwitchFromFundClassSequence: 1,
+		SwitchToFundID:              wallettest.EthereumFundID,
+		SwitchToFundClassSequence:   1,
+		RequestedAmount:             wallet.MustParseDecimal("1000"),
+	}
+	report, err := c.ValidateSwitch(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() {
+		t.Fatalf("unexpected violations %+v", report.Violations)
+	}
+
+	srv.State(func(seed *wallettest.Seed) {
+		seed.Funds[1].IsOutOfService = true
+	})
+	input.AccountID = "unknown"
+	report, err = c.ValidateSwitch(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := violationCodes(report); !slices.Equal(got, []string{wallet.ErrInsufficientAccess, wallet.ErrActionOutsideFundHours}) {
+		t.Fatalf("unexpected violations %+v", report.Violations)
+	}
+
+	input.AccountID, input.SwitchToFundClassSequence = wallettest.SingleAccountID, 9
+	report, err = c.ValidateSwitch(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := violationCodes(report); !slices.Equal(got, []string{wallet.ErrMissingResource}) || report.Violations[0].Field != "switchToFundId" {
+		t.Fatalf("unexpected violations %+v", report.Violations)
+	}
+}
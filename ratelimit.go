@@ -0,0 +1,136 @@
+package wallet
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRateLimit is the sustained number of requests per second the server allows for one key.
+	DefaultRateLimit float64 = 10
+	// DefaultRateLimitBurst is the number of requests the server allows at once for one key.
+	DefaultRateLimitBurst int = 10
+)
+
+// RateLimiter is a client-side token bucket limiter keyed by API key ID. It blocks a request
+// before it is sent until the key has a token available, so that bursts of calls are spread
+// out instead of being rejected with HTTP 429.
+//
+// A RateLimiter is safe for concurrent use and may be shared by several clients using the
+// same keys. Set it with [Options.RateLimiter].
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   int
+	limits  map[string]rateLimit
+	buckets map[string]*bucket
+}
+
+type rateLimit struct {
+	rate  float64
+	burst int
+}
+
+type bucket struct {
+	tokens float64
+	// last is the time tokens was last refilled at. It is in the future while the bucket is paused.
+	last time.Time
+}
+
+// NewRateLimiter returns a limiter allowing rate requests per second with the given burst for
+// every key ID. Values <= 0 are defaulted to [DefaultRateLimit] and [DefaultRateLimitBurst].
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		rate = DefaultRateLimit
+	}
+	if burst <= 0 {
+		burst = DefaultRateLimitBurst
+	}
+	return &RateLimiter{
+		rate:    rate,
+		burst:   burst,
+		limits:  map[string]rateLimit{},
+		buckets: map[string]*bucket{},
+	}
+}
+
+// SetLimit overrides the rate and burst for keyID. Values <= 0 are defaulted to the
+// limiter's own rate and burst.
+func (l *RateLimiter) SetLimit(keyID string, rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if rate <= 0 {
+		rate = l.rate
+	}
+	if burst <= 0 {
+		burst = l.burst
+	}
+	l.limits[keyID] = rateLimit{rate: rate, burst: burst}
+	if b, ok := l.buckets[keyID]; ok {
+		b.tokens = min(b.tokens, float64(burst))
+	}
+}
+
+func (l *RateLimiter) limit(keyID string) rateLimit {
+	if limit, ok := l.limits[keyID]; ok {
+		return limit
+	}
+	return rateLimit{rate: l.rate, burst: l.burst}
+}
+
+func (l *RateLimiter) bucket(keyID string, now time.Time) *bucket {
+	b, ok := l.buckets[keyID]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit(keyID).burst), last: now}
+		l.buckets[keyID] = b
+	}
+	return b
+}
+
+// Wait blocks until a request signed by keyID may be sent, or until ctx is done. It returns
+// how long it waited, and the context's error if ctx is done first.
+func (l *RateLimiter) Wait(ctx context.Context, keyID string) (time.Duration, error) {
+	l.mu.Lock()
+	now := time.Now()
+	limit := l.limit(keyID)
+	b := l.bucket(keyID, now)
+	if now.After(b.last) {
+		b.tokens = min(float64(limit.burst), b.tokens+now.Sub(b.last).Seconds()*limit.rate)
+		b.last = now
+	}
+	b.tokens--
+	wait := b.last.Sub(now)
+	if b.tokens < 0 {
+		wait += time.Duration(-b.tokens / limit.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return 0, nil
+	}
+	if err := sleep(ctx, wait); err != nil {
+		// give the reserved token back
+		l.mu.Lock()
+		b.tokens = min(float64(limit.burst), b.tokens+1)
+		l.mu.Unlock()
+		return time.Since(now), err
+	}
+	return wait, nil
+}
+
+// Pause holds every request signed by keyID for d, for instance after the server answered
+// with a Retry-After header. Requests resume one at a time once d elapsed.
+func (l *RateLimiter) Pause(keyID string, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	b := l.bucket(keyID, now)
+	if until := now.Add(d); until.After(b.last) {
+		b.last = until
+		b.tokens = min(b.tokens, 1)
+	}
+}
@@ -0,0 +1,132 @@
+package wallet_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	l := wallet.NewRateLimiter(20, 2)
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := l.Wait(ctx, "key"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 tokens are available at once, the other 2 are refilled at 20 per second.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Fatalf("4 requests took %s, want about 100ms", elapsed)
+	}
+	// keys are limited independently
+	if wait, err := l.Wait(ctx, "other"); err != nil || wait != 0 {
+		t.Fatalf("got wait=%s err=%v, want no wait", wait, err)
+	}
+}
+
+func TestRateLimiterContext(t *testing.T) {
+	l := wallet.NewRateLimiter(1, 1)
+	l.Pause("key", time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Wait(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRateLimiterClearsKey(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	l := wallet.NewRateLimiter(1, 1)
+	keyID, privateKeyPEM := srv.GenerateKey()
+	l.Pause(keyID, time.Minute)
+	var loaded []byte
+	c := srv.NewClient(&wallet.Options{
+		RateLimiter: l,
+		CredentialsLoaderFunc: func() (string, []byte, error) {
+			loaded = bytes.Clone(privateKeyPEM)
+			return keyID, loaded, nil
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if !bytes.Equal(loaded, make([]byte, len(loaded))) {
+		t.Fatal("expected the private key to be cleared when the call is not sent")
+	}
+}
+
+func TestRateLimiterSharedByClient(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	l := wallet.NewRateLimiter(50, 5)
+	c := srv.NewClient(&wallet.Options{RateLimiter: l})
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.ListBanks(context.Background(), &wallet.ListBanksInput{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("10 requests took %s, want at least 100ms", elapsed)
+	}
+}
+
+func TestRateLimiterAdaptsToRetryAfter(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	l := wallet.NewRateLimiter(0, 0)
+	var mu sync.Mutex
+	var waits []time.Duration
+	c := srv.NewClient(&wallet.Options{
+		RateLimiter: l,
+		Interceptors: []wallet.Interceptor{
+			func(ctx context.Context, call *wallet.Call, next wallet.Next) error {
+				err := next(ctx, call)
+				mu.Lock()
+				waits = append(waits, call.RateLimitWait)
+				mu.Unlock()
+				return err
+			},
+		},
+	})
+	srv.RateLimit("list_banks", time.Second, 1)
+	if _, err := c.ListBanks(context.Background(), &wallet.ListBanksInput{}); err != nil {
+		t.Fatal(err)
+	}
+	// a concurrent caller with the same key is held by the limiter as well.
+	start := time.Now()
+	srv.RateLimit("list_payment_methods", time.Second, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.ListPaymentMethods(context.Background(), &wallet.ListPaymentMethodsInput{})
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if _, err := c.ListBanks(context.Background(), &wallet.ListBanksInput{}); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if time.Since(start) < 900*time.Millisecond {
+		t.Fatalf("expected the limiter to pause the key after Retry-After")
+	}
+	if waits[len(waits)-1] == 0 {
+		t.Fatalf("expected the paused call to report its rate limit wait")
+	}
+}
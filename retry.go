@@ -0,0 +1,204 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy specifies how the client retries failed calls. Waits between attempts grow
+// exponentially with jitter, are capped by MaxBackoff, and are interrupted when the call's
+// context is done.
+//
+// Commands are only retried like queries when they carry an idempotency key set with
+// [WithIdempotencyKey]. Other commands are only retried on HTTP 429, since re-sending them after a
+// server or network error may duplicate a money movement.
+type RetryPolicy struct {
+	// MaxRateLimitedAttempts specifies how many times a call is sent in total while the server
+	// responds with HTTP 429. The wait honors the Retry-After header when present.
+	//
+	// Optional, defaulted to 5 attempts. Set to 1 to disable.
+	MaxRateLimitedAttempts int
+
+	// MaxServerErrorAttempts specifies how many times a call is sent in total while the server
+	// responds with HTTP status >= 500.
+	//
+	// Optional, defaulted to [Options.MaxReadRetry]. Set to 1 to disable.
+	MaxServerErrorAttempts int
+
+	// MaxNetworkErrorAttempts specifies how many times a call is sent in total while it fails
+	// with a transient network error, such as a connection reset or a timeout.
+	//
+	// Optional, defaulted to 3 attempts. Set to 1 to disable.
+	MaxNetworkErrorAttempts int
+
+	// InitialBackoff specifies the wait before the first retry.
+	//
+	// Optional, defaulted to [Options.RetryInterval].
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between two attempts, including waits asked by Retry-After.
+	//
+	// Optional, defaulted to 5 seconds.
+	MaxBackoff time.Duration
+
+	// Multiplier specifies the factor the wait grows by after each retry.
+	//
+	// Optional, defaulted to 2.
+	Multiplier float64
+
+	// Jitter specifies the fraction the wait is randomized by, for instance 0.2 for ±20%.
+	//
+	// Optional, defaulted to 0.2. Set to a negative value to disable.
+	Jitter float64
+
+	// MaxElapsedTime caps the total time spent on a call, including retries. A retry that
+	// would end after it is not attempted and the last error is returned.
+	//
+	// Optional, defaulted to 30 seconds.
+	MaxElapsedTime time.Duration
+}
+
+// withDefaults returns a copy of p with zero fields set to their defaults.
+func (p RetryPolicy) withDefaults(maxReadRetry int, retryInterval time.Duration) RetryPolicy {
+	if p.MaxRateLimitedAttempts <= 0 {
+		p.MaxRateLimitedAttempts = 5
+	}
+	if p.MaxServerErrorAttempts <= 0 {
+		p.MaxServerErrorAttempts = maxReadRetry
+	}
+	if p.MaxNetworkErrorAttempts <= 0 {
+		p.MaxNetworkErrorAttempts = 3
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = retryInterval
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 5 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	if p.Jitter == 0 {
+		p.Jitter = 0.2
+	}
+	if p.Jitter < 0 {
+		p.Jitter = 0
+	}
+	if p.MaxElapsedTime <= 0 {
+		p.MaxElapsedTime = 30 * time.Second
+	}
+	return p
+}
+
+// backoff returns the wait before the retry following the given number of retries.
+func (p RetryPolicy) backoff(retries int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 0; i < retries && d < float64(p.MaxBackoff); i++ {
+		d *= p.Multiplier
+	}
+	d = min(d, float64(p.MaxBackoff))
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(d)
+}
+
+// retryInterceptor retries calls according to p.
+func retryInterceptor(p RetryPolicy) Interceptor {
+	return func(ctx context.Context, call *Call, next Next) error {
+		start := time.Now()
+		rateLimited, serverErrors, networkErrors, retries := 0, 0, 0, 0
+		for {
+			err := next(ctx, call)
+			if err == nil || ctx.Err() != nil {
+				return err
+			}
+			wait := time.Duration(0)
+			switch {
+			case call.StatusCode == http.StatusTooManyRequests:
+				rateLimited++
+				if rateLimited >= p.MaxRateLimitedAttempts {
+					return err
+				}
+				wait = p.backoff(retries)
+				if d, ok := parseRetryAfter(call.ResponseHeader.Get("Retry-After")); ok {
+					wait = min(d, p.MaxBackoff)
+				}
+			case call.Kind != CallKindQuery && call.IdempotencyKey == "":
+				return err
+			case call.StatusCode >= http.StatusInternalServerError:
+				serverErrors++
+				if serverErrors >= p.MaxServerErrorAttempts {
+					return err
+				}
+				wait = p.backoff(retries)
+			case call.StatusCode == 0 && isTransientNetworkError(err):
+				networkErrors++
+				if networkErrors >= p.MaxNetworkErrorAttempts {
+					return err
+				}
+				wait = p.backoff(retries)
+			default:
+				return err
+			}
+			if time.Since(start)+wait > p.MaxElapsedTime {
+				return err
+			}
+			if serr := sleep(ctx, wait); serr != nil {
+				return serr
+			}
+			retries++
+		}
+	}
+}
+
+// parseRetryAfter parses a Retry-After header holding either seconds or an HTTP date.
+func parseRetryAfter(h string) (time.Duration, bool) {
+	if h == "" {
+		return 0, false
+	}
+	if i, err := strconv.ParseInt(h, 10, 64); err == nil {
+		return max(time.Duration(i)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// isTransientNetworkError reports whether err is a network failure that is likely to
+// succeed when retried.
+func isTransientNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// sleep waits for d or until ctx is done, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
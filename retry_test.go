@@ -0,0 +1,119 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func TestRetryPolicyRateLimitedAttempts(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(&wallet.Options{
+		RetryPolicy: &wallet.RetryPolicy{MaxRateLimitedAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	srv.RateLimit("list_banks", 0, 10)
+	_, err := c.ListBanks(context.Background(), &wallet.ListBanksInput{})
+	if werr, ok := err.(wallet.Error); !ok || werr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("got %v, want HTTP 429", err)
+	}
+	if n := len(srv.Calls()); n != 3 {
+		t.Fatalf("got %d calls, want 3", n)
+	}
+}
+
+func TestRetryPolicyContextCancelledDuringWait(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	srv.RateLimit("list_banks", 5*time.Second, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.ListBanks(ctx, &wallet.ListBanksInput{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("the wait was not interrupted by the context")
+	}
+}
+
+func TestRetryPolicyMaxElapsedTime(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(&wallet.Options{
+		RetryPolicy: &wallet.RetryPolicy{MaxElapsedTime: 100 * time.Millisecond},
+	})
+	srv.RateLimit("list_banks", 2*time.Second, 1)
+	start := time.Now()
+	if _, err := c.ListBanks(context.Background(), &wallet.ListBanksInput{}); err == nil {
+		t.Fatal("expected the rate limit error")
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("the retry exceeded MaxElapsedTime")
+	}
+}
+
+// newFlakyServer returns a server that drops the connection of the first n requests.
+func newFlakyServer(t *testing.T, n int32) (*httptest.Server, *atomic.Int32) {
+	var count atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if count.Add(1) <= n {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	return srv, &count
+}
+
+func TestRetryPolicyNetworkErrors(t *testing.T) {
+	srv, count := newFlakyServer(t, 2)
+	defer srv.Close()
+	fake := wallettest.NewServer(nil)
+	defer fake.Close()
+	keyID, privateKeyPEM := fake.GenerateKey()
+
+	c := wallet.New(&wallet.Options{
+		BaseURL:     srv.URL,
+		RetryPolicy: &wallet.RetryPolicy{InitialBackoff: time.Millisecond},
+	})
+	c.SetCredentials(keyID, privateKeyPEM)
+	if _, err := c.ListBanks(context.Background(), &wallet.ListBanksInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := count.Load(); n != 3 {
+		t.Fatalf("got %d requests, want 3", n)
+	}
+
+	// commands are only retried with an idempotency key.
+	count.Store(1)
+	if _, err := c.UpdateDisplayCurrency(context.Background(), &wallet.UpdateDisplayCurrencyInput{DisplayCurrency: "USD"}); err == nil {
+		t.Fatal("expected the command without idempotency key not to be retried")
+	}
+	if n := count.Load(); n != 2 {
+		t.Fatalf("got %d requests, want 2", n)
+	}
+	count.Store(1)
+	ctx := wallet.WithIdempotencyKey(context.Background(), "update-display-currency")
+	if _, err := c.UpdateDisplayCurrency(ctx, &wallet.UpdateDisplayCurrencyInput{DisplayCurrency: "USD"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := count.Load(); n != 3 {
+		t.Fatalf("got %d requests, want 3", n)
+	}
+}
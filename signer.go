@@ -0,0 +1,400 @@
+package wallet
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// JWT algorithms the token of a request may be signed with, see [Options.Algorithm].
+const (
+	// AlgorithmES256 is ECDSA on the P-256 curve with SHA-256, the default for EC P-256 keys.
+	AlgorithmES256 string = "ES256"
+	// AlgorithmES384 is ECDSA on the P-384 curve with SHA-384, the default for EC P-384 keys.
+	AlgorithmES384 string = "ES384"
+	// AlgorithmRS256 is RSASSA-PKCS1-v1_5 with SHA-256, the default for RSA keys.
+	AlgorithmRS256 string = "RS256"
+	// AlgorithmPS256 is RSASSA-PSS with SHA-256, used for RSA keys when selected.
+	AlgorithmPS256 string = "PS256"
+	// AlgorithmEdDSA is Ed25519, the default for Ed25519 keys.
+	AlgorithmEdDSA string = "EdDSA"
+)
+
+// Signer signs the token sent with every request, for instance with a key held in an HSM,
+// a cloud KMS or an OS keychain. Set it with [Options.Signer].
+//
+// Use [NewCryptoSigner] for any [crypto.Signer] and [RemoteSigner] for a remote-signing callback.
+type Signer interface {
+	// SigningKey returns the identifier of the key the next token is signed with and its
+	// algorithm, one of [AlgorithmES256], [AlgorithmES384], [AlgorithmRS256], [AlgorithmPS256]
+	// or [AlgorithmEdDSA].
+	SigningKey(ctx context.Context) (keyID string, alg string, err error)
+
+	// Sign returns the signature of digest by the key keyID returned by SigningKey. digest is
+	// the hash of the token's signing input for the algorithm: SHA-256 for ES256, RS256 and
+	// PS256, and SHA-384 for ES384. For EdDSA, which does not pre-hash, digest is the signing
+	// input itself. The signature must be ASN.1 DER encoded for ES256 and ES384, PKCS #1 v1.5
+	// for RS256, PSS with a salt as long as the hash for PS256, and raw for EdDSA.
+	Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error)
+}
+
+// algorithmFor returns the JWT algorithm signing with a key of publicKey. It is alg when set,
+// which must match the key, otherwise the default algorithm of the key.
+func algorithmFor(publicKey crypto.PublicKey, alg string) (string, error) {
+	var defaultAlg string
+	var valid []string
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			defaultAlg, valid = AlgorithmES256, []string{AlgorithmES256}
+		case elliptic.P384():
+			defaultAlg, valid = AlgorithmES384, []string{AlgorithmES384}
+		default:
+			return "", fmt.Errorf("wallet: EC key must use the P-256 or P-384 curve, got %s.", key.Curve.Params().Name)
+		}
+	case *rsa.PublicKey:
+		defaultAlg, valid = AlgorithmRS256, []string{AlgorithmRS256, AlgorithmPS256}
+	case ed25519.PublicKey:
+		defaultAlg, valid = AlgorithmEdDSA, []string{AlgorithmEdDSA}
+	default:
+		return "", fmt.Errorf("wallet: unsupported key type %T. Valid key would either be EC, RSA or Ed25519.", publicKey)
+	}
+	if alg == "" {
+		return defaultAlg, nil
+	}
+	if !slices.Contains(valid, alg) {
+		return "", fmt.Errorf("wallet: algorithm %s does not match the %s. Valid algorithm would be %s.", alg, describeKey(publicKey), strings.Join(valid, " or "))
+	}
+	return alg, nil
+}
+
+// describeKey returns the type of publicKey for error messages, for instance "EC P-384 key".
+func describeKey(publicKey crypto.PublicKey) string {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return "EC " + key.Curve.Params().Name + " key"
+	case *rsa.PublicKey:
+		return "RSA key"
+	case ed25519.PublicKey:
+		return "Ed25519 key"
+	default:
+		return fmt.Sprintf("%T key", publicKey)
+	}
+}
+
+// signingInput returns what [Signer.Sign] signs for alg: the SHA-256 or SHA-384 hash of the
+// signing string, or the signing string itself for EdDSA.
+func signingInput(alg string, signingString string) []byte {
+	switch alg {
+	case AlgorithmES384:
+		hashed := sha512.Sum384([]byte(signingString))
+		return hashed[:]
+	case AlgorithmEdDSA:
+		return []byte(signingString)
+	default:
+		hashed := sha256.Sum256([]byte(signingString))
+		return hashed[:]
+	}
+}
+
+// signerOpts returns the options of [crypto.Signer.Sign] for alg.
+func signerOpts(alg string) crypto.SignerOpts {
+	switch alg {
+	case AlgorithmES384:
+		return crypto.SHA384
+	case AlgorithmPS256:
+		return &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+	case AlgorithmEdDSA:
+		return crypto.Hash(0)
+	default:
+		return crypto.SHA256
+	}
+}
+
+type cryptoSigner struct {
+	keyID  string
+	alg    string
+	signer crypto.Signer
+}
+
+// NewCryptoSigner returns a Signer signing with signer under keyID. The algorithm follows the
+// type of signer.Public(): ES256 for an EC P-256 key, ES384 for an EC P-384 key, RS256 for an
+// RSA key and EdDSA for an Ed25519 key. Use [NewCryptoSignerWithAlgorithm] to sign with PS256.
+func NewCryptoSigner(keyID string, signer crypto.Signer) (Signer, error) {
+	return NewCryptoSignerWithAlgorithm(keyID, signer, "")
+}
+
+// NewCryptoSignerWithAlgorithm is like [NewCryptoSigner], but signs with alg, which must match
+// the key, see [Options.Algorithm]. An empty alg selects the algorithm of the key.
+func NewCryptoSignerWithAlgorithm(keyID string, signer crypto.Signer, alg string) (Signer, error) {
+	alg, err := algorithmFor(signer.Public(), alg)
+	if err != nil {
+		return nil, err
+	}
+	return &cryptoSigner{keyID: keyID, alg: alg, signer: signer}, nil
+}
+
+func (s *cryptoSigner) SigningKey(ctx context.Context) (string, string, error) {
+	return s.keyID, s.alg, nil
+}
+
+func (s *cryptoSigner) Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	// the options select PKCS #1 v1.5 or PSS for RSA keys, while EC keys produce ASN.1
+	// signatures and Ed25519 keys sign the input unhashed.
+	return s.signer.Sign(rand.Reader, digest, signerOpts(s.alg))
+}
+
+// RemoteSigner is a Signer backed by callbacks, for instance calling a remote signing service.
+type RemoteSigner struct {
+	// KeyFunc returns the identifier and the algorithm of the key the next token is signed
+	// with, see [Signer.SigningKey].
+	KeyFunc func(ctx context.Context) (keyID string, alg string, err error)
+
+	// SignFunc returns the signature of digest by keyID, see [Signer.Sign].
+	SignFunc func(ctx context.Context, keyID string, digest []byte) ([]byte, error)
+}
+
+func (s *RemoteSigner) SigningKey(ctx context.Context) (string, string, error) {
+	keyID, alg, err := s.KeyFunc(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	switch alg {
+	case AlgorithmES256, AlgorithmES384, AlgorithmRS256, AlgorithmPS256, AlgorithmEdDSA:
+		return keyID, alg, nil
+	default:
+		return "", "", fmt.Errorf("wallet: RemoteSigner: unsupported algorithm %q. Valid algorithm would be one of %s, %s, %s, %s or %s.", alg, AlgorithmES256, AlgorithmES384, AlgorithmRS256, AlgorithmPS256, AlgorithmEdDSA)
+	}
+}
+
+func (s *RemoteSigner) Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	return s.SignFunc(ctx, keyID, digest)
+}
+
+// pemSigner signs a single token with a PEM encoded private key. The key is parsed by
+// SigningKey and cleared from memory at best-effort by Sign.
+type pemSigner struct {
+	keyID          string
+	privateKeyPEM  []byte
+	shouldCleanKey bool
+	// passphraseFunc returns the passphrase of an encrypted private key, see [Options.PassphraseFunc].
+	passphraseFunc func(keyID string) ([]byte, error)
+	// algorithm is the algorithm selected by [Options.Algorithm], empty for the key's default.
+	algorithm string
+
+	key crypto.Signer
+	alg string
+}
+
+func (s *pemSigner) SigningKey(ctx context.Context) (string, string, error) {
+	key, err := parsePrivateKeyPEM(s.privateKeyPEM, s.shouldCleanKey, passphraseOf(s.keyID, s.passphraseFunc))
+	if err != nil {
+		return "", "", err
+	}
+	alg, err := algorithmFor(key.Public(), s.algorithm)
+	if err != nil {
+		clearPrivateKey(key)
+		return "", "", err
+	}
+	s.key, s.alg = key, alg
+	return s.keyID, alg, nil
+}
+
+func (s *pemSigner) Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	if s.key == nil {
+		return nil, fmt.Errorf("wallet: signAndFormat: private key was not loaded.")
+	}
+	defer func() {
+		clearPrivateKey(s.key)
+		s.key = nil
+	}()
+	return signDigest(s.key, s.alg, digest)
+}
+
+// discard clears the parsed key when the token is not signed after all, and the PEM encoded
+// key when it is to be cleaned but was not parsed.
+func (s *pemSigner) discard() {
+	if s.key != nil {
+		clearPrivateKey(s.key)
+		s.key = nil
+	}
+	if s.shouldCleanKey {
+		clear(s.privateKeyPEM)
+	}
+}
+
+// keySigner signs with a private key parsed once and held for the client's lifetime, see
+// [Client.SetCachedCredentials]. It is immutable and safe for concurrent use.
+type keySigner struct {
+	keyID string
+	alg   string
+	key   crypto.Signer
+	// header is the encoded token header for alg, reused by every token.
+	header string
+}
+
+// newKeySigner parses privateKeyPEM, decrypted with passphrase when encrypted, to sign with
+// alg, or with the key's default algorithm when alg is empty.
+func newKeySigner(keyID string, privateKeyPEM []byte, passphrase func() ([]byte, error), alg string) (*keySigner, error) {
+	key, err := parsePrivateKeyPEM(privateKeyPEM, false, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	alg, err = algorithmFor(key.Public(), alg)
+	if err != nil {
+		return nil, err
+	}
+	header, err := encodeSegment(&tokenHeader{Alg: alg, Typ: "JWT"})
+	if err != nil {
+		return nil, err
+	}
+	return &keySigner{keyID: keyID, alg: alg, key: key, header: header}, nil
+}
+
+func (s *keySigner) SigningKey(ctx context.Context) (string, string, error) {
+	return s.keyID, s.alg, nil
+}
+
+func (s *keySigner) Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	return signDigest(s.key, s.alg, digest)
+}
+
+// signDigest signs digest with an EC, RSA or Ed25519 private key, using alg.
+func signDigest(key crypto.Signer, alg string, digest []byte) ([]byte, error) {
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		signature, err := ecdsa.SignASN1(rand.Reader, key, digest)
+		if err != nil {
+			return nil, fmt.Errorf("wallet: signAndFormat: failed to sign with EC key. err=%v", err)
+		}
+		return signature, nil
+	case *rsa.PrivateKey:
+		var signature []byte
+		var err error
+		if alg == AlgorithmPS256 {
+			signature, err = rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("wallet: signAndFormat: failed to sign with RSA key. err=%v", err)
+		}
+		return signature, nil
+	case ed25519.PrivateKey:
+		return ed25519.Sign(key, digest), nil
+	default:
+		return nil, fmt.Errorf("wallet: signAndFormat: unable to cast private key type. Valid key would either be *[rsa.PrivateKey], *[ecdsa.PrivateKey] or [ed25519.PrivateKey].")
+	}
+}
+
+// parsePrivateKeyPEM parses an EC, PKCS #1 or PKCS #8 private key, or an encrypted PKCS #8
+// private key decrypted with the passphrase returned by passphrase, which may be nil. The
+// decoded DER bytes, the passphrase and the decrypted key are always cleared, and privateKeyPEM
+// is cleared when shouldCleanKey is true.
+func parsePrivateKeyPEM(privateKeyPEM []byte, shouldCleanKey bool, passphrase func() ([]byte, error)) (crypto.Signer, error) {
+	// clean up the private key from memory
+	defer func() {
+		if !shouldCleanKey {
+			return
+		}
+		for i := range privateKeyPEM {
+			privateKeyPEM[i] = 0
+		}
+	}()
+
+	privateKeyBlock, _ := pem.Decode(privateKeyPEM)
+	if privateKeyBlock == nil {
+		return nil, fmt.Errorf("wallet: signAndFormat: private key must be in PEM format.")
+	}
+	defer func() {
+		for i := range privateKeyBlock.Bytes {
+			privateKeyBlock.Bytes[i] = 0
+		}
+	}()
+	if privateKeyBlock.Headers["Proc-Type"] == "4,ENCRYPTED" {
+		return nil, fmt.Errorf("wallet: signAndFormat: legacy encrypted PEM is not supported. Convert the key to encrypted PKCS #8 with \"openssl pkcs8 -topk8\".")
+	}
+	if privateKeyBlock.Type == "ENCRYPTED PRIVATE KEY" {
+		if passphrase == nil {
+			return nil, fmt.Errorf("wallet: signAndFormat: private key is encrypted. Set PassphraseFunc upon client initialization.")
+		}
+		secret, err := passphrase()
+		if err != nil {
+			return nil, fmt.Errorf("wallet: signAndFormat: unable to get the passphrase of the private key. err=%w", err)
+		}
+		der, err := decryptPKCS8PrivateKey(privateKeyBlock.Bytes, secret)
+		clear(secret)
+		if err != nil {
+			return nil, err
+		}
+		// the decrypted key is cleared by the deferred clean up of the block.
+		privateKeyBlock.Bytes = der
+	}
+
+	var privateKeyAny any
+	var err error
+	// try EC
+	privateKeyAny, err = x509.ParseECPrivateKey(privateKeyBlock.Bytes)
+	if err != nil {
+		// try RSA
+		privateKeyAny, err = x509.ParsePKCS1PrivateKey(privateKeyBlock.Bytes)
+		if err != nil {
+			privateKeyAny, err = x509.ParsePKCS8PrivateKey(privateKeyBlock.Bytes)
+			if err != nil && privateKeyBlock.Type == "ENCRYPTED PRIVATE KEY" {
+				// the padding was valid by chance, but the passphrase was not.
+				return nil, ErrIncorrectPassphrase
+			}
+			if err != nil {
+				return nil, fmt.Errorf("wallet: signAndFormat: unable to deduce private key type. Valid key would either be EC, RSA or Ed25519.")
+			}
+		}
+	}
+	switch key := privateKeyAny.(type) {
+	case *ecdsa.PrivateKey:
+		return key, nil
+	case *rsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("wallet: signAndFormat: unable to cast private key type. Valid key would either be *[rsa.PrivateKey], *[ecdsa.PrivateKey] or [ed25519.PrivateKey].")
+	}
+}
+
+// passphraseOf binds passphraseFunc to keyID, returning nil when passphraseFunc is nil.
+func passphraseOf(keyID string, passphraseFunc func(keyID string) ([]byte, error)) func() ([]byte, error) {
+	if passphraseFunc == nil {
+		return nil
+	}
+	return func() ([]byte, error) {
+		return passphraseFunc(keyID)
+	}
+}
+
+// clearPrivateKey overwrites the secret parts of key at best-effort.
+func clearPrivateKey(key crypto.Signer) {
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		key.D = big.NewInt(0)
+		key.X = big.NewInt(0)
+		key.Y = big.NewInt(0)
+	case *rsa.PrivateKey:
+		key.D = big.NewInt(0)
+		key.N = big.NewInt(0)
+	case ed25519.PrivateKey:
+		clear(key)
+	}
+}
@@ -0,0 +1,288 @@
+package wallet_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func TestCryptoSigner(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+
+	for keyID, key := range map[string]crypto.Signer{"ec-key": ecKey, "rsa-key": rsaKey} {
+		srv.RegisterKey(keyID, key.Public())
+		signer, err := wallet.NewCryptoSigner(keyID, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := srv.NewClient(&wallet.Options{Signer: signer})
+		if _, err := c.ListClientAccounts(context.Background(), &wallet.ListClientAccountsInput{}); err != nil {
+			t.Fatalf("%s: %v", keyID, err)
+		}
+	}
+
+	p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wallet.NewCryptoSigner("p521-key", p521Key); err == nil {
+		t.Fatal("expected a P-521 key to be rejected")
+	}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	remote := wallettest.NewSigner("RS256")
+	srv.RegisterSigner(remote)
+
+	alg := "RS256"
+	c := srv.NewClient(&wallet.Options{
+		Signer: &wallet.RemoteSigner{
+			KeyFunc: func(ctx context.Context) (string, string, error) {
+				return remote.KeyID, alg, nil
+			},
+			SignFunc: remote.Sign,
+		},
+	})
+	ctx := context.Background()
+	if _, err := c.ListClientAccounts(ctx, &wallet.ListClientAccountsInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if remote.Signed() != 1 {
+		t.Fatalf("got %d signatures, want 1", remote.Signed())
+	}
+
+	alg = "HS256"
+	if _, err := c.ListClientAccounts(ctx, &wallet.ListClientAccountsInput{}); err == nil {
+		t.Fatal("expected an unsupported algorithm to be rejected")
+	}
+	if remote.Signed() != 1 {
+		t.Fatalf("got %d signatures, want 1", remote.Signed())
+	}
+}
+
+func TestCachedCredentials(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	keyID, privateKeyPEM := srv.GenerateKey()
+	if err := c.SetCachedCredentials(keyID, privateKeyPEM); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetCachedCredentials(keyID, []byte("invalid")); err == nil {
+		t.Fatal("expected an invalid key to be rejected")
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.ListClientAccounts(context.Background(), &wallet.ListClientAccountsInput{})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, call := range srv.Calls() {
+		if call.KeyID != keyID {
+			t.Fatalf("got key %q, want %q", call.KeyID, keyID)
+		}
+	}
+}
+
+func TestRotateCredentials(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	ctx := context.Background()
+
+	keyIDs := map[string]bool{}
+	for _, cached := range []bool{false, true} {
+		c := srv.NewClient(nil)
+		keyID, privateKeyPEM := srv.GenerateKey()
+		if cached {
+			if err := c.SetCachedCredentials(keyID, privateKeyPEM); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			c.SetCredentials(keyID, privateKeyPEM)
+		}
+		if err := c.RotateCredentials("other", []byte("invalid")); err == nil {
+			t.Fatal("expected an invalid key to be rejected")
+		}
+
+		keyIDs[keyID] = true
+		var wg sync.WaitGroup
+		errs := make(chan error, 100)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					_, err := c.ListClientAccounts(ctx, &wallet.ListClientAccountsInput{})
+					errs <- err
+				}
+			}()
+		}
+		for i := 0; i < 5; i++ {
+			keyID, privateKeyPEM := srv.GenerateKey()
+			keyIDs[keyID] = true
+			if err := c.RotateCredentials(keyID, privateKeyPEM); err != nil {
+				t.Fatal(err)
+			}
+			// the key may be cleared by the caller once rotated.
+			clear(privateKeyPEM)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		keyID, privateKeyPEM = srv.GenerateKey()
+		keyIDs[keyID] = true
+		if err := c.RotateCredentials(keyID, privateKeyPEM); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.ListClientAccounts(ctx, &wallet.ListClientAccountsInput{}); err != nil {
+			t.Fatal(err)
+		}
+		calls := srv.Calls()
+		if last := calls[len(calls)-1]; last.KeyID != keyID {
+			t.Fatalf("got key %q after rotation, want %q", last.KeyID, keyID)
+		}
+		for _, call := range calls {
+			if !keyIDs[call.KeyID] {
+				t.Fatalf("got unexpected key %q", call.KeyID)
+			}
+		}
+	}
+
+	keyID, privateKeyPEM := srv.GenerateKey()
+	c := srv.NewClient(&wallet.Options{
+		CredentialsLoaderFunc: func() (string, []byte, error) { return keyID, privateKeyPEM, nil },
+	})
+	if err := c.RotateCredentials(keyID, privateKeyPEM); err == nil {
+		t.Fatal("expected rotation to be refused with CredentialsLoaderFunc")
+	}
+}
+
+func TestSigningAlgorithms(t *testing.T) {
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toPEM := func(key crypto.Signer) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
+
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	ctx := context.Background()
+	tests := []struct {
+		keyID     string
+		key       crypto.Signer
+		algorithm string
+		want      string
+	}{
+		{"p384-key", p384Key, "", wallet.AlgorithmES384},
+		{"rsa-key", rsaKey, "", wallet.AlgorithmRS256},
+		{"rsa-pss-key", rsaKey, wallet.AlgorithmPS256, wallet.AlgorithmPS256},
+		{"ed25519-key", edKey, "", wallet.AlgorithmEdDSA},
+	}
+	for _, tt := range tests {
+		srv.RegisterKey(tt.keyID, tt.key.Public())
+		c := srv.NewClient(&wallet.Options{Algorithm: tt.algorithm})
+		c.SetCredentials(tt.keyID, toPEM(tt.key))
+		if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); err != nil {
+			t.Fatalf("%s: %v", tt.keyID, err)
+		}
+
+		signer, err := wallet.NewCryptoSignerWithAlgorithm(tt.keyID, tt.key, tt.algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, alg, err := signer.SigningKey(ctx); err != nil || alg != tt.want {
+			t.Fatalf("%s: got algorithm %s, want %s (%v)", tt.keyID, alg, tt.want, err)
+		}
+		c = srv.NewClient(&wallet.Options{Signer: signer})
+		if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); err != nil {
+			t.Fatalf("%s: %v", tt.keyID, err)
+		}
+	}
+
+	// a server restricted to some algorithms rejects the others.
+	srv.SetAlgorithms(wallet.AlgorithmES256, wallet.AlgorithmPS256)
+	c := srv.NewClient(&wallet.Options{})
+	c.SetCredentials("rsa-key", toPEM(rsaKey))
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); !errors.Is(err, wallet.Error{Code: wallet.ErrInvalidAuthToken}) {
+		t.Fatalf("expected RS256 to be rejected, got %v", err)
+	}
+	c = srv.NewClient(&wallet.Options{Algorithm: wallet.AlgorithmPS256})
+	c.SetCredentials("rsa-key", toPEM(rsaKey))
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// mismatched algorithms fail locally, before any request is sent.
+	calls := len(srv.Calls())
+	c = srv.NewClient(&wallet.Options{Algorithm: wallet.AlgorithmES256})
+	c.SetCredentials("p384-key", toPEM(p384Key))
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); err == nil || !strings.Contains(err.Error(), "EC P-384 key") {
+		t.Fatalf("expected a curve mismatch error, got %v", err)
+	}
+	if _, err := wallet.NewCryptoSignerWithAlgorithm("ed25519-key", edKey, wallet.AlgorithmPS256); err == nil {
+		t.Fatal("expected PS256 to be rejected for an Ed25519 key")
+	}
+	c = srv.NewClient(&wallet.Options{Algorithm: "HS256"})
+	c.SetCredentials("rsa-key", toPEM(rsaKey))
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); err == nil {
+		t.Fatal("expected an unsupported algorithm to be rejected")
+	}
+	if got := len(srv.Calls()); got != calls {
+		t.Fatalf("got %d calls, want %d", got, calls)
+	}
+}
@@ -0,0 +1,146 @@
+package wallet
+
+// validator is implemented by inputs checked before being sent.
+type validator interface {
+	validate() []ErrorDetail
+}
+
+// validate returns an [Error] listing the invalid fields of input, if any. Its Code is the code
+// of the first invalid field, such as [ErrInvalidParameter] or [ErrInvalidDateRange].
+func validate(name string, input any) error {
+	v, ok := input.(validator)
+	if !ok {
+		return nil
+	}
+	details := v.validate()
+	if len(details) == 0 {
+		return nil
+	}
+	return Error{
+		Code:    details[0].Code,
+		Message: details[0].Message,
+		Details: details,
+		APIName: name,
+	}
+}
+
+func (input *GetClientAccountAllocationPerformanceInput) validate() (details []ErrorDetail) {
+	details = checkEnum(details, "timeframe", input.Timeframe, timeframes)
+	return checkEnum(details, "interval", input.Interval, intervals)
+}
+
+func (input *ListClientAccountPerformanceInput) validate() (details []ErrorDetail) {
+	details = checkEnum(details, "timeframe", input.Timeframe, timeframes)
+	return checkEnum(details, "interval", input.Interval, intervals)
+}
+
+func (input *GetClientAccountStatementInput) validate() (details []ErrorDetail) {
+	details = checkDateRange(details, input.FromDate, input.ToDate)
+	return checkEnum(details, "format", input.Format, documentFormats)
+}
+
+func (input *GetClientAccountRequestConfirmationInput) validate() []ErrorDetail {
+	return checkEnum(nil, "format", input.Format, documentFormats)
+}
+
+func (input *ListClientAccountRequestsInput) validate() (details []ErrorDetail) {
+	if input.FromDate != nil && input.ToDate != nil {
+		details = checkDateRange(details, *input.FromDate, *input.ToDate)
+	}
+	for _, t := range input.Types {
+		details = checkEnum(details, "types", t, requestTypes)
+	}
+	for _, s := range input.Statuses {
+		details = checkEnum(details, "statuses", s, requestStatuses)
+	}
+	return details
+}
+
+func (input *CreateInvestmentRequestInput) validate() (details []ErrorDetail) {
+	details = checkRequired(details, "accountId", input.AccountID)
+	details = checkFundClass(details, "fundId", input.FundID, "fundClassSequence", input.FundClassSequence)
+	return checkAmount(details, "amount", input.Amount)
+}
+
+func (input *CreateRedemptionRequestInput) validate() (details []ErrorDetail) {
+	details = checkRequired(details, "accountId", input.AccountID)
+	details = checkFundClass(details, "fundId", input.FundID, "fundClassSequence", input.FundClassSequence)
+	return checkAmountOrUnits(details, input.RequestedAmount, input.Units)
+}
+
+func (input *CreateSwitchRequestInput) validate() (details []ErrorDetail) {
+	details = checkRequired(details, "accountId", input.AccountID)
+	details = checkFundClass(details, "switchFromFundId", input.SwitchFromFundID, "switchFromFundClassSequence", input.SwitchFromFundClassSequence)
+	details = checkFundClass(details, "switchToFundId", input.SwitchToFundID, "switchToFundClassSequence", input.SwitchToFundClassSequence)
+	return checkAmountOrUnits(details, input.RequestedAmount, input.Units)
+}
+
+func (input *CreateRequestCancellationInput) validate() (details []ErrorDetail) {
+	details = checkRequired(details, "accountId", input.AccountID)
+	return checkRequired(details, "requestId", input.RequestID)
+}
+
+func (input *CreateSuitabilityAssessmentInput) validate() []ErrorDetail {
+	if input.SuitabilityAssessment == nil {
+		return []ErrorDetail{{Field: "suitabilityAssessment", Code: ErrMissingParameter, Message: "suitabilityAssessment is required"}}
+	}
+	return nil
+}
+
+func (input *CreateClientBankAccountInput) validate() []ErrorDetail {
+	if input.BankAccount == nil {
+		return []ErrorDetail{{Field: "bankAccount", Code: ErrMissingParameter, Message: "bankAccount is required"}}
+	}
+	return checkRequired(nil, "bankAccount.accountNumber", input.BankAccount.AccountNumber)
+}
+
+func (input *UpdateDisplayCurrencyInput) validate() []ErrorDetail {
+	return checkRequired(nil, "displayCurrency", input.DisplayCurrency)
+}
+
+func (input *UpdateAccountNameInput) validate() (details []ErrorDetail) {
+	details = checkRequired(details, "accountId", input.AccountID)
+	return checkRequired(details, "accountName", input.AccountName)
+}
+
+// checkRequired reports field as missing when v is empty.
+func checkRequired(details []ErrorDetail, field string, v string) []ErrorDetail {
+	if v != "" {
+		return details
+	}
+	return append(details, ErrorDetail{Field: field, Code: ErrMissingParameter, Message: field + " is required"})
+}
+
+// checkFundClass reports a missing fund ID or class sequence.
+func checkFundClass(details []ErrorDetail, fundField string, fundID string, sequenceField string, sequence int) []ErrorDetail {
+	details = checkRequired(details, fundField, fundID)
+	if sequence <= 0 {
+		details = append(details, ErrorDetail{Field: sequenceField, Code: ErrMissingParameter, Message: sequenceField + " is required"})
+	}
+	return details
+}
+
+// checkAmount reports a zero amount as missing, and a negative one as invalid.
+func checkAmount(details []ErrorDetail, field string, d Decimal) []ErrorDetail {
+	switch d.Sign() {
+	case 0:
+		return append(details, ErrorDetail{Field: field, Code: ErrMissingParameter, Message: field + " is required"})
+	case -1:
+		return append(details, ErrorDetail{Field: field, Code: ErrInvalidParameter, Message: field + " must be positive"})
+	}
+	return details
+}
+
+// checkAmountOrUnits requires either an amount or a number of units, neither of them negative.
+func checkAmountOrUnits(details []ErrorDetail, amount Decimal, units Decimal) []ErrorDetail {
+	if amount.IsZero() && units.IsZero() {
+		return append(details, ErrorDetail{Field: "requestedAmount", Code: ErrMissingParameter, Message: "either requestedAmount or units is required"})
+	}
+	if amount.Sign() < 0 {
+		details = checkAmount(details, "requestedAmount", amount)
+	}
+	if units.Sign() < 0 {
+		details = checkAmount(details, "units", units)
+	}
+	return details
+}
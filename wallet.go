@@ -1,9 +1,14 @@
 package wallet
 
 import (
+	"bytes"
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,9 +21,34 @@ const (
 	AccountExperienceDim            string = "dim"
 )
 
+// Client calls the Halogen Wallet API. Create it with [New].
+//
+// A Client is safe for concurrent use by multiple goroutines, including while its credentials
+// are changed with [Client.SetCredentials], [Client.SetCachedCredentials] or
+// [Client.RotateCredentials]: each request is signed with the credentials current when it is
+// signed, and in-flight requests are not interrupted. Its configuration is copied by New and
+// cannot be changed afterwards.
 type Client struct {
+	// options is the copy of the options made by New, never modified afterwards.
 	options     *Options
-	credentials *credentials
+	credentials atomic.Pointer[credentials]
+
+	// baseURL is the resolved server URL without a trailing slash.
+	baseURL string
+	// basePath is the path component of baseURL, prepended to the uri claim.
+	basePath string
+	// err holds the configuration error detected by New, if any. It is
+	// returned by every call made through the client.
+	err error
+	// pipeline is the interceptor chain ending with roundTrip.
+	pipeline Next
+	// readOnly and dryRun are copied from the options by New, so that they cannot be
+	// changed once the client is created.
+	readOnly bool
+	dryRun   bool
+	// logger and logBodyLimit are resolved from the options by New.
+	logger       *slog.Logger
+	logBodyLimit int
 }
 
 type Options struct {
@@ -30,6 +60,35 @@ type Options struct {
 	// at best-effort cleared from the memory post call.
 	CredentialsLoaderFunc func() (keyID string, privateKeyPEM []byte, err error)
 
+	// PassphraseFunc returns the passphrase of the private key keyID when it is an encrypted
+	// PKCS #8 key, in an "ENCRYPTED PRIVATE KEY" PEM block. PBES2 with PBKDF2 or scrypt, and
+	// AES-CBC or AES-GCM are supported, which covers the keys encrypted by OpenSSL and by
+	// [GenerateKeyPair]. The returned passphrase and the decrypted key are cleared from memory
+	// at best-effort once the key is parsed, so PassphraseFunc must return a new slice on every call.
+	//
+	// It is called whenever the key is parsed, that is for every request with CredentialsLoaderFunc
+	// and [wallet.Client.SetCredentials], and once with [wallet.Client.SetCachedCredentials], which
+	// avoids decrypting the key for every request.
+	//
+	// Optional, if not set, encrypted private keys are rejected.
+	PassphraseFunc func(keyID string) (passphrase []byte, err error)
+
+	// Algorithm selects the JWT algorithm the token of every request is signed with, when the
+	// server supports several for the key, for instance [AlgorithmPS256] instead of
+	// [AlgorithmRS256] for an RSA key. It must match the key: a mismatch, such as [AlgorithmES256]
+	// with an EC P-384 key, fails the request before it is sent.
+	//
+	// Optional, defaulted to the algorithm of the key: ES256 for EC P-256, ES384 for EC P-384,
+	// RS256 for RSA and EdDSA for Ed25519 keys. Ignored when Signer is set.
+	Algorithm string
+
+	// Signer signs the token of every request, for instance with a key held in an HSM or a
+	// cloud KMS, see [Signer], or with one of several keys of a [KeyRing]. When set,
+	// CredentialsLoaderFunc and [wallet.Client.SetCredentials] are ignored.
+	//
+	// Optional.
+	Signer Signer
+
 	// HTTPClient specifies an HTTP client used to call the server
 	//
 	// Optional.
@@ -37,72 +96,243 @@ type Options struct {
 
 	// MaxReadRetry specifies how many times to retry a query request when fails.
 	//
-	// Optional, defaulted to 5 times.
+	// Optional, defaulted to 5 times. Superseded by [RetryPolicy.MaxServerErrorAttempts] when set.
 	MaxReadRetry int
 
 	// RetryInterval specifies how long to wait before retrying a query request when fails.
 	//
-	// Optional, defaulted to 50 milliseconds.
+	// Optional, defaulted to 50 milliseconds. Superseded by [RetryPolicy.InitialBackoff] when set.
 	RetryInterval time.Duration
 
-	// Debug reports whether the client is running in debug mode which enables logging.
+	// RetryPolicy specifies how failed calls are retried, see [RetryPolicy].
+	//
+	// Optional, defaulted to the zero RetryPolicy which gives every field its default.
+	RetryPolicy *RetryPolicy
+
+	// Debug reports whether the client is running in debug mode which enables logging. When
+	// Logger is not set, calls are logged at debug level to the output of the standard logger,
+	// with their bodies up to 64 KiB unless LogBodyLimit is set.
 	//
 	// Optional, defaulted to false.
 	Debug bool
+
+	// Logger receives a record for every call, once it is done: at debug level when it succeeds
+	// and at warn level when it fails, with the API name, the HTTP status, the duration, the
+	// number of attempts and the key ID as attributes. The token and the personal data of the
+	// client, such as NRIC, passport and tax numbers, emails, addresses and bank accounts, are
+	// never logged, except in the exact payload of the commands of DryRun, at debug level.
+	//
+	// Optional, if not set, nothing is logged except with Debug, and the commands of DryRun
+	// are logged to [slog.Default].
+	Logger *slog.Logger
+
+	// LogBodyLimit specifies how many bytes of each request and response body are logged at
+	// debug level, with personal data redacted.
+	//
+	// Optional, defaulted to 0 which disables body logging.
+	LogBodyLimit int
+
+	// Environment specifies the Halogen Wallet deployment the client talks to. Value
+	// can be one of [EnvironmentProduction], [EnvironmentSandbox] or [EnvironmentCustom].
+	//
+	// Optional, defaulted to [EnvironmentProduction], or to [EnvironmentCustom] when BaseURL is set.
+	Environment Environment
+
+	// BaseURL specifies the server URL the "/query" and "/command" paths are appended to,
+	// for instance a staging gateway, an egress proxy path such as "https://proxy.internal/wallet"
+	// or an [net/http/httptest.Server] URL. Any path in BaseURL is also reflected in the
+	// "uri" claim of the signed token.
+	//
+	// Required when Environment is [EnvironmentCustom], must be empty otherwise.
+	BaseURL string
+
+	// RateLimiter throttles requests on the client side before they are sent, see [RateLimiter].
+	// Share one limiter between clients using the same keys.
+	//
+	// Optional, if not set, the client only reacts to HTTP 429 responses.
+	RateLimiter *RateLimiter
+
+	// Interceptors wrap every query and command, for instance to add logging, metrics,
+	// auditing, caching or request mutation. The first interceptor is the outermost one.
+	//
+	// Interceptors run before the client's own retries, so each of them sees one call per
+	// method invocation, while [Call.Attempt] reports how many times it was sent.
+	//
+	// Optional.
+	Interceptors []Interceptor
+
+	// ReadOnly refuses every command, such as CreateInvestmentRequest or UpdateAccountName, before
+	// it reaches the interceptors, with an error wrapping [ErrReadOnly]. Queries are sent as usual.
+	// It is fixed for the lifetime of the client: changing it after New has no effect.
+	//
+	// Optional, defaulted to false.
+	ReadOnly bool
+
+	// DryRun signs commands without sending them. The redacted payload and the claims of the
+	// token are logged at info level to Logger, and the exact signed payload, personal data
+	// included, at debug level. The command returns a synthetic output: its identifiers, such as RequestID, start with
+	// [DryRunIDPrefix] and its other fields, such as Existing, are left at their zero value.
+	// Commands still go through the interceptors and the input validation, and queries are sent
+	// as usual. It is fixed for the lifetime of the client: changing it after New has no effect.
+	//
+	// Optional, defaulted to false.
+	DryRun bool
 }
 
+// New returns a client configured with a copy of the first of opts, if any. Options that
+// are not set are filled in with their defaults in the copy: opts is never modified, and
+// changing it after New has no effect on the client. HTTPClient is copied too, so that its
+// default timeout is not set on the caller's [http.Client], but its Transport is shared.
+//
+// An invalid Environment or BaseURL does not panic; every call made through the returned
+// client fails with the configuration error instead.
 func New(opts ...*Options) *Client {
-	defaultOptions := Options{
-		HTTPClient:    &http.Client{Timeout: 10 * time.Second},
-		MaxReadRetry:  5,
-		RetryInterval: 50 * time.Millisecond,
+	var o Options
+	if len(opts) > 0 && opts[0] != nil {
+		o = *opts[0]
 	}
-	if len(opts) == 0 {
-		return &Client{
-			options: &defaultOptions,
-		}
-	}
-	o := opts[0]
 	// HTTP options
-	if o.HTTPClient == nil {
-		o.HTTPClient = defaultOptions.HTTPClient
+	httpClient := http.Client{}
+	if o.HTTPClient != nil {
+		httpClient = *o.HTTPClient
 	}
 	// force timeout in HTTP client
-	if o.HTTPClient.Timeout <= 0 {
-		o.HTTPClient.Timeout = 10 * time.Second
+	if httpClient.Timeout <= 0 {
+		httpClient.Timeout = 10 * time.Second
 	}
+	o.HTTPClient = &httpClient
 
 	// retry options
 	if o.MaxReadRetry <= 0 {
-		o.MaxReadRetry = defaultOptions.MaxReadRetry
+		o.MaxReadRetry = 5
 	}
 	if o.RetryInterval <= 0 {
-		o.RetryInterval = defaultOptions.RetryInterval
+		o.RetryInterval = 50 * time.Millisecond
+	}
+	if o.RetryPolicy != nil {
+		retryPolicy := *o.RetryPolicy
+		o.RetryPolicy = &retryPolicy
 	}
+	o.Interceptors = slices.Clone(o.Interceptors)
 
-	return &Client{
-		options: o,
+	return newClient(&o)
+}
+
+// newClient resolves the server URL of o. An invalid configuration does not fail New,
+// instead it is reported by every call made through the returned client.
+func newClient(o *Options) *Client {
+	c := &Client{
+		options:      o,
+		readOnly:     o.ReadOnly,
+		dryRun:       o.DryRun,
+		logger:       newLogger(o),
+		logBodyLimit: o.LogBodyLimit,
+	}
+	if o.Debug && o.Logger == nil && c.logBodyLimit <= 0 {
+		c.logBodyLimit = debugLogBodyLimit
+	}
+	interceptors := append([]Interceptor{}, o.Interceptors...)
+	retryPolicy := RetryPolicy{}
+	if o.RetryPolicy != nil {
+		retryPolicy = *o.RetryPolicy
 	}
+	interceptors = append(interceptors, c.logInterceptor)
+	if ring, ok := o.Signer.(*KeyRing); ok {
+		interceptors = append(interceptors, ring.failover)
+	}
+	interceptors = append(interceptors, retryInterceptor(retryPolicy.withDefaults(o.MaxReadRetry, o.RetryInterval)))
+	c.pipeline = chain(interceptors, c.roundTrip)
+	switch o.Algorithm {
+	case "", AlgorithmES256, AlgorithmES384, AlgorithmRS256, AlgorithmPS256, AlgorithmEdDSA:
+	default:
+		c.err = fmt.Errorf("wallet: unsupported algorithm %q. Valid algorithm would be one of %s, %s, %s, %s or %s.", o.Algorithm, AlgorithmES256, AlgorithmES384, AlgorithmRS256, AlgorithmPS256, AlgorithmEdDSA)
+		return c
+	}
+	baseURL, err := resolveBaseURL(o.Environment, o.BaseURL)
+	if err != nil {
+		c.err = err
+		return c
+	}
+	c.baseURL = strings.TrimRight(baseURL.String(), "/")
+	c.basePath = strings.TrimRight(baseURL.EscapedPath(), "/")
+	return c
 }
 
 type credentials struct {
 	keyID         string
 	privateKeyPEM []byte
+	// signer holds the parsed key when set with SetCachedCredentials.
+	signer *keySigner
 }
 
-// SetCredentials sets credentials to the client instance. If [wallet.Options.CredentialsLoaderFunc] is set
-// upon client's initialization then this is ignored.
+// SetCredentials sets credentials to the client instance. If [wallet.Options.CredentialsLoaderFunc] or
+// [wallet.Options.Signer] is set upon client's initialization then this is ignored.
 func (c *Client) SetCredentials(keyID string, privateKeyPEM []byte) {
-	if c.options.CredentialsLoaderFunc != nil {
-		if c.options.Debug {
-			log.Println("INFO: ignoring SetCredentials call as CredentialsLoaderFunc was set to the client.")
-		}
+	if c.ignoreCredentials("SetCredentials") {
 		return
 	}
-	c.credentials = &credentials{
+	c.credentials.Store(&credentials{
 		keyID:         keyID,
 		privateKeyPEM: privateKeyPEM,
+	})
+}
+
+// SetCachedCredentials sets credentials to the client instance like [wallet.Client.SetCredentials], but
+// parses the private key once and keeps it in memory for the client's lifetime instead of parsing it
+// for every request. This trades the per-request clean up of the key for throughput, for instance when
+// polling many accounts. It is safe to call while requests are in flight.
+//
+// It returns an error if the private key is invalid, in which case the previous credentials are kept.
+func (c *Client) SetCachedCredentials(keyID string, privateKeyPEM []byte) error {
+	if c.ignoreCredentials("SetCachedCredentials") {
+		return nil
+	}
+	signer, err := newKeySigner(keyID, privateKeyPEM, passphraseOf(keyID, c.options.PassphraseFunc), c.options.Algorithm)
+	if err != nil {
+		return err
 	}
+	c.credentials.Store(&credentials{
+		keyID:  keyID,
+		signer: signer,
+	})
+	return nil
+}
+
+// RotateCredentials replaces the credentials of the client in a single atomic step, for
+// instance when a key is rotated in the Halogen platform. The private key is validated first:
+// if it is invalid, an error is returned and the previous credentials are kept. The client
+// keeps parsing the key for every request, or caching it, as set by the previous call to
+// [wallet.Client.SetCredentials] or [wallet.Client.SetCachedCredentials]. privateKeyPEM is
+// copied, so it may be cleared by the caller once RotateCredentials returns.
+//
+// Requests in flight are not interrupted: a request already signed keeps the previous key,
+// and any request signed after RotateCredentials returns uses the new one.
+//
+// It returns an error if [wallet.Options.CredentialsLoaderFunc] or [wallet.Options.Signer] was
+// set, as the client does not own its credentials then.
+func (c *Client) RotateCredentials(keyID string, privateKeyPEM []byte) error {
+	if c.options.CredentialsLoaderFunc != nil || c.options.Signer != nil {
+		return fmt.Errorf("wallet: RotateCredentials: credentials are provided by CredentialsLoaderFunc or Signer.")
+	}
+	privateKeyPEM = bytes.Clone(privateKeyPEM)
+	signer, err := newKeySigner(keyID, privateKeyPEM, passphraseOf(keyID, c.options.PassphraseFunc), c.options.Algorithm)
+	if err != nil {
+		return err
+	}
+	creds := &credentials{keyID: keyID, privateKeyPEM: privateKeyPEM}
+	if current := c.credentials.Load(); current != nil && current.signer != nil {
+		creds = &credentials{keyID: keyID, signer: signer}
+	}
+	c.credentials.Store(creds)
+	return nil
+}
+
+func (c *Client) ignoreCredentials(method string) bool {
+	if c.options.CredentialsLoaderFunc == nil && c.options.Signer == nil {
+		return false
+	}
+	c.logger.Debug("wallet: ignoring " + method + " call as CredentialsLoaderFunc or Signer was set to the client")
+	return true
 }
 
 // ClientAccount represents Halogen investment account. One client may have many accounts.
@@ -144,37 +374,37 @@ type ClientAccount struct {
 	Asset string `json:"asset,omitempty"`
 
 	// PortfolioValue specifies the value of this account in Asset terms
-	PortfolioValue float64 `json:"portfolioValue"`
+	PortfolioValue Decimal `json:"portfolioValue"`
 
 	// ExposurePercentage specifies the exposure of this account relatively to the total
 	// value of other accounts
-	ExposurePercentage float64 `json:"exposurePercentage"`
+	ExposurePercentage Decimal `json:"exposurePercentage"`
 
 	// PnlAmount specifies the profit or loss amount in Asset terms.
 	//
 	// The value will be negative when it is a loss.
-	PnlAmount float64 `json:"pnlAmount"`
+	PnlAmount Decimal `json:"pnlAmount"`
 
 	// PnlAmount specifies the percentage of profit or loss relative
 	// to the invested amount.
 	//
 	// The value will be negative when it is a loss.
-	PnlPercentage float64 `json:"pnlPercentage"`
+	PnlPercentage Decimal `json:"pnlPercentage"`
 
 	// NetInflow specifies the net total traded in this account
-	NetInflow float64 `json:"netInflow"`
+	NetInflow Decimal `json:"netInflow"`
 
 	// TotalInflow specifies the total amount that has been injected
 	// into this account.
-	TotalInflow float64 `json:"totalInflow"`
+	TotalInflow Decimal `json:"totalInflow"`
 
 	// TotalOutflow specifies the total amount that has been redeemed
 	// from this account.
-	TotalOutflow float64 `json:"totalOutflow"`
+	TotalOutflow Decimal `json:"totalOutflow"`
 
 	// PendingSwitchInAmount specifies the total switching amount that is pending
 	// confirmation.
-	PendingSwitchInAmount float64 `json:"pendingSwitchInAmount"`
+	PendingSwitchInAmount Decimal `json:"pendingSwitchInAmount"`
 
 	RiskLabel       string `json:"riskLabel"`
 	RiskDescription string `json:"riskDescription"`
@@ -217,7 +447,7 @@ type ListClientAccountsInput struct {
 
 type ListClientAccountsOutput struct {
 	// Amount is the total value of all returned accounts.
-	Amount float64 `json:"amount"`
+	Amount Decimal `json:"amount"`
 	// Asset specifies the Amount's asset.
 	//
 	// In case the display currency is updated then the amount will be
@@ -332,7 +562,7 @@ type GetClientProfileOutput struct {
 	// 		- None of the above.
 	// 		- Allowed to invest in Private Mandate, DIM.
 	//
-	InvestorCategory string `json:"investorCategory,omitempty"`
+	InvestorCategory InvestorCategory `json:"investorCategory,omitempty"`
 
 	// CountryOfIncoporation specifies the origin country of a corporate.
 	//
@@ -475,7 +705,7 @@ type Fund struct {
 
 	// RiskRating specifies the amount of risk this fund has in text format. Value is one of
 	// "low", "moderate", "high".
-	RiskRating string `json:"riskRating,omitempty"`
+	RiskRating RiskRating `json:"riskRating,omitempty"`
 	// RiskScore specifies the amount of risk this fund has in numeric format. Value is range
 	// from 5 (low) to 16 (high).
 	RiskScore int `json:"riskScore,omitempty"`
@@ -491,7 +721,7 @@ type Fund struct {
 	ShariahCompliant bool `json:"shariahCompliant,omitempty"`
 
 	// Status specifies the status of the fund. Value is one of "pending", "active" or "archived".
-	Status string `json:"status,omitempty"`
+	Status FundStatus `json:"status,omitempty"`
 
 	// TagLine specifies the marketing line where it outlines the feature of the fund.
 	TagLine string `json:"tagLine,omitempty"`
@@ -504,7 +734,7 @@ type Fund struct {
 	ImageUrl string `json:"imageUrl,omitempty"`
 
 	// CreatedAt specifies the date-time of which the fund was created on.
-	CreatedAt string `json:"createdAt,omitempty"`
+	CreatedAt Timestamp `json:"createdAt,omitzero"`
 
 	// Classes specifies the fund's classes.
 	Classes []FundClass `json:"classes,omitempty"`
@@ -523,27 +753,27 @@ type FundClass struct {
 	Sequence                    int                    `json:"sequence,omitempty"`
 	Label                       string                 `json:"label,omitempty"`
 	BaseCurrency                string                 `json:"baseCurrency,omitempty"`
-	ManagementFee               float64                `json:"managementFee,omitempty"`
-	TrusteeFee                  float64                `json:"trusteeFee,omitempty"`
-	CustodianFee                float64                `json:"custodianFee,omitempty"`
-	TransferFee                 float64                `json:"transferFee,omitempty"`
-	TrusteeFeeAnnualMinimum     float64                `json:"trusteeFeeAnnualMinimum,omitempty"`
-	SwitchingFee                float64                `json:"switchingFee,omitempty"`
-	SubscriptionFee             float64                `json:"subscriptionFee,omitempty"`
-	RedemptionFee               float64                `json:"redemptionFee,omitempty"`
-	PerformanceFee              float64                `json:"performanceFee,omitempty"`
-	TaxRate                     float64                `json:"taxRate,omitempty"`
-	MinimumInitialInvestment    float64                `json:"minimumInitialInvestment,omitempty"`
-	MinimumAdditionalInvestment float64                `json:"minimumAdditionalInvestment,omitempty"`
-	MinimumUnitsHeld            float64                `json:"minimumUnitsHeld,omitempty"`
-	MinimumRedemptionAmount     float64                `json:"minimumRedemptionAmount,omitempty"`
+	ManagementFee               Decimal                `json:"managementFee,omitzero"`
+	TrusteeFee                  Decimal                `json:"trusteeFee,omitzero"`
+	CustodianFee                Decimal                `json:"custodianFee,omitzero"`
+	TransferFee                 Decimal                `json:"transferFee,omitzero"`
+	TrusteeFeeAnnualMinimum     Decimal                `json:"trusteeFeeAnnualMinimum,omitzero"`
+	SwitchingFee                Decimal                `json:"switchingFee,omitzero"`
+	SubscriptionFee             Decimal                `json:"subscriptionFee,omitzero"`
+	RedemptionFee               Decimal                `json:"redemptionFee,omitzero"`
+	PerformanceFee              Decimal                `json:"performanceFee,omitzero"`
+	TaxRate                     Decimal                `json:"taxRate,omitzero"`
+	MinimumInitialInvestment    Decimal                `json:"minimumInitialInvestment,omitzero"`
+	MinimumAdditionalInvestment Decimal                `json:"minimumAdditionalInvestment,omitzero"`
+	MinimumUnitsHeld            Decimal                `json:"minimumUnitsHeld,omitzero"`
+	MinimumRedemptionAmount     Decimal                `json:"minimumRedemptionAmount,omitzero"`
 	CanDistribute               bool                   `json:"canDistribute,omitempty"`
-	LaunchPrice                 float64                `json:"launchPrice,omitempty"`
+	LaunchPrice                 Decimal                `json:"launchPrice,omitzero"`
 	HexColor                    string                 `json:"hexColor,omitempty"`
-	CommencementAt              string                 `json:"commencementAt,omitempty"`
-	InitialOfferingPeriodFrom   string                 `json:"initialOfferingPeriodFrom,omitempty"`
-	InitialOfferingPeriodTo     string                 `json:"initialOfferingPeriodTo,omitempty"`
-	CreatedAt                   string                 `json:"createdAt,omitempty"`
+	CommencementAt              Timestamp              `json:"commencementAt,omitzero"`
+	InitialOfferingPeriodFrom   Date                   `json:"initialOfferingPeriodFrom,omitzero"`
+	InitialOfferingPeriodTo     Date                   `json:"initialOfferingPeriodTo,omitzero"`
+	CreatedAt                   Timestamp              `json:"createdAt,omitzero"`
 	DistributionFrequency       string                 `json:"distributionFrequency,omitempty"`
 	TagLine                     string                 `json:"tagLine,omitempty"`
 	Metadata                    map[string]interface{} `json:"metadata,omitempty"`
@@ -582,21 +812,21 @@ func (c *Client) GetFund(ctx context.Context, input *GetFundInput) (output *GetF
 }
 
 type AllocationPerformance struct {
-	Date                 string  `json:"date,omitempty"`
-	Units                float64 `json:"units,omitempty"`
+	Date                 Date    `json:"date,omitzero"`
+	Units                Decimal `json:"units,omitzero"`
 	Asset                string  `json:"asset,omitempty"`
-	NetAssetValuePerUnit float64 `json:"netAssetValuePerUnit,omitempty"`
-	Value                float64 `json:"value,omitempty"`
-	PostFeeAmount        float64 `json:"postFeeAmount,omitempty"`
+	NetAssetValuePerUnit Decimal `json:"netAssetValuePerUnit,omitzero"`
+	Value                Decimal `json:"value,omitzero"`
+	PostFeeAmount        Decimal `json:"postFeeAmount,omitzero"`
 }
 
 type GetClientAccountAllocationPerformanceInput struct {
-	AccountID         string `json:"accountId,omitempty"`
-	AllocationID      string `json:"allocationId,omitempty"`
-	Type              string `json:"type,omitempty"`
-	FundClassSequence int    `json:"fundClassSequence,omitempty"`
-	Timeframe         string `json:"timeframe,omitempty"`
-	Interval          string `json:"interval,omitempty"`
+	AccountID         string    `json:"accountId,omitempty"`
+	AllocationID      string    `json:"allocationId,omitempty"`
+	Type              string    `json:"type,omitempty"`
+	FundClassSequence int       `json:"fundClassSequence,omitempty"`
+	Timeframe         Timeframe `json:"timeframe,omitempty"`
+	Interval          Interval  `json:"interval,omitempty"`
 }
 
 type GetClientAccountAllocationPerformanceOutput struct {
@@ -633,18 +863,18 @@ func (c *Client) GetClientAccountAllocationPerformance(ctx context.Context, inpu
 }
 
 type GetClientAccountStatementInput struct {
-	AccountID string `json:"accountId,omitempty"`
-	FromDate  string `json:"fromDate,omitempty"`
-	ToDate    string `json:"toDate,omitempty"`
-	Format    string `json:"format"`
+	AccountID string         `json:"accountId,omitempty"`
+	FromDate  Date           `json:"fromDate,omitzero"`
+	ToDate    Date           `json:"toDate,omitzero"`
+	Format    DocumentFormat `json:"format"`
 }
 
 type GetClientAccountStatementOutput struct {
-	FromDate string `json:"fromDate,omitempty"`
-	ToDate   string `json:"toDate,omitempty"`
-	Format   string `json:"format,omitempty"`
-	Filename string `json:"filename,omitempty"`
-	Bytes    []byte `json:"bytes,omitempty"`
+	FromDate Date           `json:"fromDate,omitzero"`
+	ToDate   Date           `json:"toDate,omitzero"`
+	Format   DocumentFormat `json:"format,omitempty"`
+	Filename string         `json:"filename,omitempty"`
+	Bytes    []byte         `json:"bytes,omitempty"`
 }
 
 // GetClientAccountStatement retrieves the account statement as a document (PDF or HTML) for transactions within a specified date range.
@@ -676,15 +906,15 @@ func (c *Client) GetClientAccountStatement(ctx context.Context, input *GetClient
 }
 
 type GetClientAccountRequestConfirmationInput struct {
-	AccountID string `json:"accountId,omitempty"`
-	RequestID string `json:"requestId,omitempty"`
-	Format    string `json:"format,omitempty"`
+	AccountID string         `json:"accountId,omitempty"`
+	RequestID string         `json:"requestId,omitempty"`
+	Format    DocumentFormat `json:"format,omitempty"`
 }
 
 type GetClientAccountRequestConfirmationOutput struct {
-	Format   string `json:"format,omitempty"`
-	Filename string `json:"filename,omitempty"`
-	Bytes    []byte `json:"bytes,omitempty"`
+	Format   DocumentFormat `json:"format,omitempty"`
+	Filename string         `json:"filename,omitempty"`
+	Bytes    []byte         `json:"bytes,omitempty"`
 }
 
 // GetClientAccountRequestConfirmation retrieves the confirmation document for a specific investment, redemption, or switch request.
@@ -749,11 +979,11 @@ type PolicyGroup struct {
 }
 
 type PolicyParticipant struct {
-	Email      string `json:"email,omitempty"`
-	GroupLabel string `json:"groupLabel,omitempty"`
-	Name       string `json:"name,omitempty"`
-	Signed     bool   `json:"signed,omitempty"`
-	SignedAt   string `json:"signedAt,omitempty"`
+	Email      string    `json:"email,omitempty"`
+	GroupLabel string    `json:"groupLabel,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	Signed     bool      `json:"signed,omitempty"`
+	SignedAt   Timestamp `json:"signedAt,omitzero"`
 }
 
 type GetClientAccountRequestPolicyInput struct {
@@ -823,26 +1053,26 @@ func (c *Client) ListFundsForSubscription(ctx context.Context, input *ListFundsF
 }
 
 type Balance struct {
-	FundID                    string   `json:"fundId,omitempty"`
-	FundClassSequence         int      `json:"fundClassSequence,omitempty"`
-	FundName                  string   `json:"fundName,omitempty"`
-	FundShortName             string   `json:"fundShortName,omitempty"`
-	FundClassLabel            string   `json:"fundClassLabel,omitempty"`
-	FundCode                  string   `json:"fundCode,omitempty"`
-	FundImageUrl              string   `json:"fundImageUrl,omitempty"`
-	Units                     float64  `json:"units,omitempty"`
-	Asset                     string   `json:"asset,omitempty"`
-	Value                     float64  `json:"value,omitempty"`
-	ValuedAt                  string   `json:"valuedAt,omitempty"`
-	MinimumRedemptionAmount   float64  `json:"minimumRedemptionAmount,omitempty"`
-	MinimumRedemptionUnits    float64  `json:"minimumRedemptionUnits,omitempty"`
-	MinimumSubscriptionAmount float64  `json:"minimumSubscriptionAmount,omitempty"`
-	MinimumSubscriptionUnits  float64  `json:"minimumSubscriptionUnits,omitempty"`
-	RedemptionFeePercentage   float64  `json:"redemptionFeePercentage,omitempty"`
-	SwitchFeePercentage       float64  `json:"switchFeePercentage,omitempty"`
-	AvailableModes            []string `json:"availableModes"`
-	IsOutOfService            bool     `json:"isOutOfService"`
-	OutOfServiceMessage       string   `json:"outOfServiceMessage,omitempty"`
+	FundID                    string    `json:"fundId,omitempty"`
+	FundClassSequence         int       `json:"fundClassSequence,omitempty"`
+	FundName                  string    `json:"fundName,omitempty"`
+	FundShortName             string    `json:"fundShortName,omitempty"`
+	FundClassLabel            string    `json:"fundClassLabel,omitempty"`
+	FundCode                  string    `json:"fundCode,omitempty"`
+	FundImageUrl              string    `json:"fundImageUrl,omitempty"`
+	Units                     Decimal   `json:"units,omitzero"`
+	Asset                     string    `json:"asset,omitempty"`
+	Value                     Decimal   `json:"value,omitzero"`
+	ValuedAt                  Timestamp `json:"valuedAt,omitzero"`
+	MinimumRedemptionAmount   Decimal   `json:"minimumRedemptionAmount,omitzero"`
+	MinimumRedemptionUnits    Decimal   `json:"minimumRedemptionUnits,omitzero"`
+	MinimumSubscriptionAmount Decimal   `json:"minimumSubscriptionAmount,omitzero"`
+	MinimumSubscriptionUnits  Decimal   `json:"minimumSubscriptionUnits,omitzero"`
+	RedemptionFeePercentage   Decimal   `json:"redemptionFeePercentage,omitzero"`
+	SwitchFeePercentage       Decimal   `json:"switchFeePercentage,omitzero"`
+	AvailableModes            []string  `json:"availableModes"`
+	IsOutOfService            bool      `json:"isOutOfService"`
+	OutOfServiceMessage       string    `json:"outOfServiceMessage,omitempty"`
 }
 
 type ListClientAccountBalanceInput struct {
@@ -878,42 +1108,42 @@ func (c *Client) ListClientAccountBalance(ctx context.Context, input *ListClient
 }
 
 type BankAccount struct {
-	AccountNumber   string `json:"accountNumber,omitempty"`
-	AccountName     string `json:"accountName,omitempty"`
-	AccountCurrency string `json:"accountCurrency,omitempty"`
-	AccountType     string `json:"accountType,omitempty"`
-	BankName        string `json:"bankName,omitempty"`
-	BankBic         string `json:"bankBic,omitempty"`
-	ReferenceNumber string `json:"referenceNumber,omitempty"`
-	ImageUrl        string `json:"imageUrl,omitempty"`
-	Status          string `json:"status,omitempty"`
-	Source          string `json:"source,omitempty"`
-	CreatedAt       string `json:"createdAt,omitempty"`
-	CreatedBy       string `json:"createdBy,omitempty"`
+	AccountNumber   string    `json:"accountNumber,omitempty"`
+	AccountName     string    `json:"accountName,omitempty"`
+	AccountCurrency string    `json:"accountCurrency,omitempty"`
+	AccountType     string    `json:"accountType,omitempty"`
+	BankName        string    `json:"bankName,omitempty"`
+	BankBic         string    `json:"bankBic,omitempty"`
+	ReferenceNumber string    `json:"referenceNumber,omitempty"`
+	ImageUrl        string    `json:"imageUrl,omitempty"`
+	Status          string    `json:"status,omitempty"`
+	Source          string    `json:"source,omitempty"`
+	CreatedAt       Timestamp `json:"createdAt,omitzero"`
+	CreatedBy       string    `json:"createdBy,omitempty"`
 }
 
 type ClientAccountRequest struct {
 	ID string `json:"id,omitempty"`
 	// fundmanagement: investment, redemption, switch out, switch in
 	// dim: deposit, withdrawal
-	Type string `json:"type,omitempty"`
+	Type RequestType `json:"type,omitempty"`
 
 	FundID         string `json:"fundId,omitempty"`
 	FundName       string `json:"fundName,omitempty"`
 	FundShortName  string `json:"fundShortName,omitempty"`
 	FundClassLabel string `json:"fundClassLabel,omitempty"`
 
-	Asset                string   `json:"asset,omitempty"`
-	Amount               float64  `json:"amount,omitempty"`
-	PostFeeAmount        float64  `json:"postFeeAmount,omitempty"`
-	Units                float64  `json:"units,omitempty"`
-	UnitPrice            *float64 `json:"unitPrice,omitempty"`
-	FeePercentage        float64  `json:"feePercentage,omitempty"`
-	StrokedFeePercentage float64  `json:"strokedFeePercentage,omitempty"`
-	FeeAmount            float64  `json:"feeAmount,omitempty"`
-	RebateFromDate       string   `json:"rebateFromDate,omitempty"`
-	RebateToDate         string   `json:"rebateToDate,omitempty"`
-	Status               string   `json:"status,omitempty"`
+	Asset                string        `json:"asset,omitempty"`
+	Amount               Decimal       `json:"amount,omitzero"`
+	PostFeeAmount        Decimal       `json:"postFeeAmount,omitzero"`
+	Units                Decimal       `json:"units,omitzero"`
+	UnitPrice            *Decimal      `json:"unitPrice,omitempty"`
+	FeePercentage        Decimal       `json:"feePercentage,omitzero"`
+	StrokedFeePercentage Decimal       `json:"strokedFeePercentage,omitzero"`
+	FeeAmount            Decimal       `json:"feeAmount,omitzero"`
+	RebateFromDate       Date          `json:"rebateFromDate,omitzero"`
+	RebateToDate         Date          `json:"rebateToDate,omitzero"`
+	Status               RequestStatus `json:"status,omitempty"`
 
 	VoucherCode   *string `json:"voucherCode,omitempty"`
 	ConsentType   *string `json:"consentType,omitempty"`
@@ -921,22 +1151,22 @@ type ClientAccountRequest struct {
 
 	CollectionBankAccount *BankAccount `json:"collectionBankAccount,omitempty"`
 
-	CreatedAt string `json:"createdAt,omitempty"`
+	CreatedAt Timestamp `json:"createdAt,omitzero"`
 }
 
 type ListClientAccountRequestsInput struct {
 	AccountID string  `json:"accountId,omitempty"`
 	RequestID *string `json:"requestId,omitempty"`
 	// Deprecated: Use FundIDs instead.
-	FundID        *string   `json:"fundId,omitempty"`
-	FundIDs       []*string `json:"fundIds,omitempty"`
-	FromDate      *string   `json:"fromDate,omitempty"`
-	ToDate        *string   `json:"toDate,omitempty"`
-	Types         []*string `json:"types,omitempty"`
-	Statuses      []*string `json:"statuses,omitempty"`
-	Limit         *int      `json:"limit,omitempty"`
-	Offset        *int      `json:"offset,omitempty"`
-	CompletedOnly bool      `json:"completedOnly,omitempty"`
+	FundID        *string         `json:"fundId,omitempty"`
+	FundIDs       []*string       `json:"fundIds,omitempty"`
+	FromDate      *Date           `json:"fromDate,omitempty"`
+	ToDate        *Date           `json:"toDate,omitempty"`
+	Types         []RequestType   `json:"types,omitempty"`
+	Statuses      []RequestStatus `json:"statuses,omitempty"`
+	Limit         *int            `json:"limit,omitempty"`
+	Offset        *int            `json:"offset,omitempty"`
+	CompletedOnly bool            `json:"completedOnly,omitempty"`
 }
 
 type ListClientAccountRequestsOutput struct {
@@ -1040,19 +1270,19 @@ func (c *Client) ListDisplayCurrencies(ctx context.Context, input *ListDisplayCu
 }
 
 type SuitabilityAssessment struct {
-	ID                   string `json:"id,omitempty"`
-	ClientID             string `json:"clientId,omitempty"`
-	Source               string `json:"source,omitempty"`
-	InvestmentExperience string `json:"investmentExperience,omitempty"`
-	InvestmentObjective  string `json:"investmentObjective,omitempty"`
-	InvestmentHorizon    string `json:"investmentHorizon,omitempty"`
-	CurrentInvestment    string `json:"currentInvestment,omitempty"`
-	ReturnExpectations   string `json:"returnExpectations,omitempty"`
-	Attachment           string `json:"attachment,omitempty"`
-	TotalScore           int    `json:"totalScore,omitempty"`
-	RiskTolerance        string `json:"riskTolerance,omitempty"`
-	CreatedBy            string `json:"createdBy,omitempty"`
-	CreatedAt            string `json:"createdAt,omitempty"`
+	ID                   string    `json:"id,omitempty"`
+	ClientID             string    `json:"clientId,omitempty"`
+	Source               string    `json:"source,omitempty"`
+	InvestmentExperience string    `json:"investmentExperience,omitempty"`
+	InvestmentObjective  string    `json:"investmentObjective,omitempty"`
+	InvestmentHorizon    string    `json:"investmentHorizon,omitempty"`
+	CurrentInvestment    string    `json:"currentInvestment,omitempty"`
+	ReturnExpectations   string    `json:"returnExpectations,omitempty"`
+	Attachment           string    `json:"attachment,omitempty"`
+	TotalScore           int       `json:"totalScore,omitempty"`
+	RiskTolerance        string    `json:"riskTolerance,omitempty"`
+	CreatedBy            string    `json:"createdBy,omitempty"`
+	CreatedAt            Timestamp `json:"createdAt,omitzero"`
 }
 
 type ListClientSuitabilityAssessmentsInput struct {
@@ -1163,16 +1393,16 @@ func (c *Client) ListBanks(ctx context.Context, input *ListBanksInput) (output *
 }
 
 type Promo struct {
-	AccountID          string  `json:"accountId,omitempty"`
-	AccountName        string  `json:"accountName,omitempty"`
-	Code               string  `json:"code,omitempty"`
-	Label              string  `json:"label,omitempty"`
-	Description        string  `json:"description,omitempty"`
-	DiscountPercentage float64 `json:"discountPercentage,omitempty"`
-	DiscountFrom       string  `json:"discountFrom,omitempty"`
-	ValidFromDate      *string `json:"validFromDate,omitempty"`
-	ValidToDate        *string `json:"validToDate,omitempty"`
-	CreatedAt          string  `json:"createdAt,omitempty"`
+	AccountID          string    `json:"accountId,omitempty"`
+	AccountName        string    `json:"accountName,omitempty"`
+	Code               string    `json:"code,omitempty"`
+	Label              string    `json:"label,omitempty"`
+	Description        string    `json:"description,omitempty"`
+	DiscountPercentage Decimal   `json:"discountPercentage,omitzero"`
+	DiscountFrom       string    `json:"discountFrom,omitempty"`
+	ValidFromDate      *Date     `json:"validFromDate,omitempty"`
+	ValidToDate        *Date     `json:"validToDate,omitempty"`
+	CreatedAt          Timestamp `json:"createdAt,omitzero"`
 }
 
 type ListClientPromosInput struct {
@@ -1204,15 +1434,15 @@ func (c *Client) ListClientPromos(ctx context.Context, input *ListClientPromosIn
 }
 
 type ClientAccountPerformance struct {
-	Date      string  `json:"date,omitempty"`
+	Date      Date    `json:"date,omitzero"`
 	AccountID string  `json:"accountId,omitempty"`
-	Value     float64 `json:"value,omitempty"`
+	Value     Decimal `json:"value,omitzero"`
 }
 
 type ListClientAccountPerformanceInput struct {
-	AccountIDs []string `json:"accountIds,omitempty"`
-	Timeframe  string   `json:"timeframe,omitempty"`
-	Interval   string   `json:"interval,omitempty"`
+	AccountIDs []string  `json:"accountIds,omitempty"`
+	Timeframe  Timeframe `json:"timeframe,omitempty"`
+	Interval   Interval  `json:"interval,omitempty"`
 }
 
 type ListClientAccountPerformanceOutput struct {
@@ -1278,18 +1508,18 @@ type GetVoucherInput struct {
 	AccountID         string  `json:"accountId,omitempty"`
 	FundID            string  `json:"fundId,omitempty"`
 	FundClassSequence int     `json:"fundClassSequence,omitempty"`
-	Amount            float64 `json:"amount,omitempty"`
+	Amount            Decimal `json:"amount,omitzero"`
 	VoucherCode       *string `json:"voucherCode,omitempty"`
 }
 
 type GetVoucherOutput struct {
 	Valid                            bool    `json:"valid"`
 	Code                             string  `json:"code"`
-	StrokedSubscriptionFeePercentage float64 `json:"strokedSubscriptionFeePercentage"`
-	AppliedSubscriptionFeePercentage float64 `json:"appliedSubscriptionFeePercentage"`
-	VoucherDiscountPercentage        float64 `json:"voucherDiscountPercentage"`
-	FeeAmount                        float64 `json:"feeAmount"`
-	PostFeeAmount                    float64 `json:"postFeeAmount"`
+	StrokedSubscriptionFeePercentage Decimal `json:"strokedSubscriptionFeePercentage"`
+	AppliedSubscriptionFeePercentage Decimal `json:"appliedSubscriptionFeePercentage"`
+	VoucherDiscountPercentage        Decimal `json:"voucherDiscountPercentage"`
+	FeeAmount                        Decimal `json:"feeAmount"`
+	PostFeeAmount                    Decimal `json:"postFeeAmount"`
 }
 
 // GetVoucher retrieves details and validates a specific voucher code, calculating the discounted fees for an investment.
@@ -1324,14 +1554,14 @@ type GetPreviewInvestInput struct {
 	AccountID         string  `json:"accountId,omitempty"`
 	FundID            string  `json:"fundId,omitempty"`
 	FundClassSequence int     `json:"fundClassSequence,omitempty"`
-	Amount            float64 `json:"amount,omitempty"`
+	Amount            Decimal `json:"amount,omitzero"`
 }
 
 type GetPreviewInvestOutput struct {
-	StrokedSubscriptionFeePercentage float64           `json:"strokedSubscriptionFeePercentage"`
-	AppliedSubscriptionFeePercentage float64           `json:"appliedSubscriptionFeePercentage"`
-	PostFeeAmount                    float64           `json:"postFeeAmount"`
-	FeeAmount                        float64           `json:"feeAmount"`
+	StrokedSubscriptionFeePercentage Decimal           `json:"strokedSubscriptionFeePercentage"`
+	AppliedSubscriptionFeePercentage Decimal           `json:"appliedSubscriptionFeePercentage"`
+	PostFeeAmount                    Decimal           `json:"postFeeAmount"`
+	FeeAmount                        Decimal           `json:"feeAmount"`
 	DefaultVoucher                   *GetVoucherOutput `json:"defaultVoucher,omitempty"`
 }
 
@@ -1369,7 +1599,7 @@ type GetProjectedFundPriceInput struct {
 
 type GetProjectedFundPriceOutput struct {
 	Asset                string  `json:"asset"`
-	NetAssetValuePerUnit float64 `json:"netAssetValuePerUnit"`
+	NetAssetValuePerUnit Decimal `json:"netAssetValuePerUnit"`
 }
 
 // GetProjectedFundPrice retrieves the projected unit net asset value per unit (NAV per unit) for a specific fund class.
@@ -1404,7 +1634,7 @@ type CreateInvestmentRequestInput struct {
 	// FundClassSequence specifies the class of the fund to invest in.
 	FundClassSequence int `json:"fundClassSequence,omitempty"`
 	// Amount specifies the amount to be invested.
-	Amount float64 `json:"amount,omitempty"`
+	Amount Decimal `json:"amount,omitzero"`
 
 	// ConsentFundIM is deprecated, use Consents instead.
 	ConsentFundIM bool `json:"consentFundIM,omitempty"`
@@ -1422,6 +1652,9 @@ type CreateInvestmentRequestInput struct {
 type CreateInvestmentRequestOutput struct {
 	// RequestID specifies the identifier of the created investment request.
 	RequestID string `json:"requestId,omitempty"`
+	// Existing reports whether the server returned the request previously created with the
+	// same idempotency key instead of creating a new one.
+	Existing bool `json:"existing,omitempty"`
 }
 
 // CreateInvestmentRequest submits a new investment request to purchase units in a specified fund class with the provided amount.
@@ -1467,9 +1700,9 @@ type CreateRedemptionRequestInput struct {
 	// FundClassSequence specifies the class of the fund to redeem from.
 	FundClassSequence int `json:"fundClassSequence,omitempty"`
 	// RequestedAmount specifies the amount to redeem.
-	RequestedAmount float64 `json:"requestedAmount,omitempty"`
+	RequestedAmount Decimal `json:"requestedAmount,omitzero"`
 	// Units specifies the number of units to redeem.
-	Units float64 `json:"units,omitempty"`
+	Units Decimal `json:"units,omitzero"`
 	// ToBankAccountNumber specifies the bank account number for the redemption proceeds.
 	ToBankAccountNumber string `json:"toBankAccountNumber,omitempty"`
 }
@@ -1478,6 +1711,9 @@ type CreateRedemptionRequestInput struct {
 type CreateRedemptionRequestOutput struct {
 	// RequestID specifies the identifier of the created redemption request.
 	RequestID string `json:"requestId,omitempty"`
+	// Existing reports whether the server returned the request previously created with the
+	// same idempotency key instead of creating a new one.
+	Existing bool `json:"existing,omitempty"`
 }
 
 // CreateRedemptionRequest submits a new redemption request to sell fund units or withdraw an amount from an account.
@@ -1527,15 +1763,18 @@ type CreateSwitchRequestInput struct {
 	SwitchToFundClassSequence int `json:"switchToFundClassSequence,omitempty"`
 
 	// RequestedAmount specifies the amount to switch.
-	RequestedAmount float64 `json:"requestedAmount,omitempty"`
+	RequestedAmount Decimal `json:"requestedAmount,omitzero"`
 	// Units specifies the number of units to switch.
-	Units float64 `json:"units,omitempty"`
+	Units Decimal `json:"units,omitzero"`
 }
 
 // CreateSwitchRequestOutput represents the response for a switch request.
 type CreateSwitchRequestOutput struct {
 	// RequestID specifies the identifier of the created switch request.
 	RequestID string `json:"requestId,omitempty"`
+	// Existing reports whether the server returned the request previously created with the
+	// same idempotency key instead of creating a new one.
+	Existing bool `json:"existing,omitempty"`
 }
 
 // CreateSwitchRequest submits a request to transfer units from one fund to another within the same account.
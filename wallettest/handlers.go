@@ -0,0 +1,841 @@
+package wallettest
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+)
+
+// handler serves one API. It is called with the server lock held.
+type handler func(s *Server, payload json.RawMessage) (any, *apiError)
+
+var queryHandlers map[string]handler
+
+var commandHandlers map[string]handler
+
+func init() {
+	queryHandlers = map[string]handler{
+		"list_client_accounts": listClientAccounts,
+		"get_client_profile":   getClientProfile,
+		"get_fund":             getFund,
+		"get_client_account_allocation_performance": getClientAccountAllocationPerformance,
+		"get_client_account_statement":              getClientAccountStatement,
+		"get_client_account_request_confirmation":   getClientAccountRequestConfirmation,
+		"get_client_referral":                       getClientReferral,
+		"get_client_account_request_policy":         getClientAccountRequestPolicy,
+		"list_funds_for_subscription":               listFundsForSubscription,
+		"list_client_account_balance":               listClientAccountBalance,
+		"list_client_account_requests":              listClientAccountRequests,
+		"list_client_bank_accounts":                 listClientBankAccounts,
+		"list_display_currencies":                   listDisplayCurrencies,
+		"list_client_suitability_assessments":       listClientSuitabilityAssessments,
+		"list_invest_consents":                      listInvestConsents,
+		"list_banks":                                listBanks,
+		"list_client_promos":                        listClientPromos,
+		"list_client_account_performance":           listClientAccountPerformance,
+		"list_payment_methods":                      listPaymentMethods,
+		"get_voucher":                               getVoucher,
+		"get_preview_invest":                        getPreviewInvest,
+		"get_projected_fund_price":                  getProjectedFundPrice,
+	}
+	commandHandlers = map[string]handler{
+		"create_investment_request":     createInvestmentRequest,
+		"create_redemption_request":     createRedemptionRequest,
+		"create_switch_request":         createSwitchRequest,
+		"create_request_cancellation":   createRequestCancellation,
+		"create_suitability_assessment": createSuitabilityAssessment,
+		"create_client_bank_account":    createClientBankAccount,
+		"update_display_currency":       updateDisplayCurrency,
+		"update_account_name":           updateAccountName,
+		"update_client_profile":         updateClientProfile,
+	}
+}
+
+func decode(payload json.RawMessage, v any) *apiError {
+	if err := json.Unmarshal(payload, v); err != nil {
+		return errorf(wallet.ErrInvalidPayload, "payload is invalid: %v", err)
+	}
+	return nil
+}
+
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprintf("%040x", s.nextID)
+}
+
+func (s *Server) account(id string) (*wallet.ClientAccount, *apiError) {
+	if id == "" {
+		return nil, fieldErrorf(wallet.ErrMissingParameter, "accountId", "accountId is required")
+	}
+	for i := range s.state.Accounts {
+		if s.state.Accounts[i].ID == id {
+			return &s.state.Accounts[i], nil
+		}
+	}
+	return nil, errorf(wallet.ErrInsufficientAccess, "account %q is not accessible", id)
+}
+
+func (s *Server) fund(id string) (*wallet.Fund, *apiError) {
+	if id == "" {
+		return nil, fieldErrorf(wallet.ErrMissingParameter, "fundId", "fundId is required")
+	}
+	for i := range s.state.Funds {
+		if s.state.Funds[i].ID == id {
+			return &s.state.Funds[i], nil
+		}
+	}
+	return nil, errorf(wallet.ErrMissingResource, "fund %q does not exist", id)
+}
+
+func (s *Server) fundClass(fundID string, sequence int) (*wallet.Fund, *wallet.FundClass, *apiError) {
+	f, aerr := s.fund(fundID)
+	if aerr != nil {
+		return nil, nil, aerr
+	}
+	for i := range f.Classes {
+		if f.Classes[i].Sequence == sequence {
+			return f, &f.Classes[i], nil
+		}
+	}
+	return nil, nil, errorf(wallet.ErrMissingResource, "fund class %d does not exist", sequence)
+}
+
+func (s *Server) balance(accountID string, fundID string, sequence int) *wallet.Balance {
+	for _, b := range s.state.Balances[accountID] {
+		if b.FundID == fundID && b.FundClassSequence == sequence {
+			return b
+		}
+	}
+	return nil
+}
+
+func (s *Server) request(accountID string, requestID string) (*wallet.ClientAccountRequest, *apiError) {
+	if requestID == "" {
+		return nil, fieldErrorf(wallet.ErrMissingParameter, "requestId", "requestId is required")
+	}
+	for i, r := range s.state.Requests[accountID] {
+		if r.ID == requestID {
+			return &s.state.Requests[accountID][i], nil
+		}
+	}
+	return nil, errorf(wallet.ErrMissingResource, "request %q does not exist", requestID)
+}
+
+func (s *Server) price(fundID string, sequence int) wallet.Decimal {
+	return s.state.Prices[FundClassKey{FundID: fundID, Sequence: sequence}]
+}
+
+// addRequest appends r to the account and, for joint accounts, attaches a two-signatory policy.
+func (s *Server) addRequest(account *wallet.ClientAccount, r wallet.ClientAccountRequest) string {
+	r.ID = s.newID()
+	r.Status = wallet.RequestStatusPending
+	r.CreatedAt = now()
+	s.state.Requests[account.ID] = append(s.state.Requests[account.ID], r)
+	if account.Type == wallet.AccountTypeJoint {
+		s.state.Policies[r.ID] = wallet.GetClientAccountRequestPolicyOutput{
+			Groups: []wallet.PolicyGroup{{Label: "holders", Min: 2, Max: 2}},
+			Participants: []wallet.PolicyParticipant{
+				{Email: "primary@example.com", GroupLabel: "holders", Name: "Primary", Signed: true, SignedAt: r.CreatedAt},
+				{Email: "secondary@example.com", GroupLabel: "holders", Name: "Secondary"},
+			},
+		}
+	}
+	return r.ID
+}
+
+func checkDateRange(fromDate wallet.Date, toDate wallet.Date) *apiError {
+	if !fromDate.IsZero() && !toDate.IsZero() && fromDate.After(toDate) {
+		return errorf(wallet.ErrInvalidDateRange, "fromDate must not be after toDate")
+	}
+	return nil
+}
+
+// now returns the current time truncated to the second, as the server stores timestamps.
+func now() wallet.Timestamp {
+	return wallet.Timestamp{Time: time.Now().UTC().Truncate(time.Second)}
+}
+
+//
+// Queries
+//
+
+func listClientAccounts(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.ListClientAccountsInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	output := wallet.ListClientAccountsOutput{
+		Asset:    s.state.DisplayCurrency,
+		Accounts: []wallet.ClientAccount{},
+	}
+	for _, id := range input.AccountIDs {
+		if _, aerr := s.account(id); aerr != nil {
+			return nil, aerr
+		}
+	}
+	for _, a := range s.state.Accounts {
+		if len(input.AccountIDs) > 0 && !slices.Contains(input.AccountIDs, a.ID) {
+			continue
+		}
+		a.PortfolioValue = wallet.Decimal{}
+		for _, b := range s.state.Balances[a.ID] {
+			a.PortfolioValue = a.PortfolioValue.Add(b.Units.Mul(s.price(b.FundID, b.FundClassSequence)).Round(wallet.CurrencyScale, wallet.RoundHalfUp))
+		}
+		output.Amount = output.Amount.Add(a.PortfolioValue)
+		output.Accounts = append(output.Accounts, a)
+	}
+	return output, nil
+}
+
+func getClientProfile(s *Server, payload json.RawMessage) (any, *apiError) {
+	return s.state.Profile, nil
+}
+
+func getFund(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.GetFundInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	f, aerr := s.fund(input.FundID)
+	if aerr != nil {
+		return nil, aerr
+	}
+	return wallet.GetFundOutput{Fund: f}, nil
+}
+
+func getClientAccountAllocationPerformance(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.GetClientAccountAllocationPerformanceInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if _, aerr := s.account(input.AccountID); aerr != nil {
+		return nil, aerr
+	}
+	if input.AllocationID == "" {
+		return nil, fieldErrorf(wallet.ErrMissingParameter, "allocationId", "allocationId is required")
+	}
+	output := wallet.GetClientAccountAllocationPerformanceOutput{Performance: []wallet.AllocationPerformance{}}
+	b := s.balance(input.AccountID, input.AllocationID, input.FundClassSequence)
+	if b == nil {
+		return output, nil
+	}
+	nav := s.price(b.FundID, b.FundClassSequence)
+	for i, d := range []wallet.Date{wallet.NewDate(2025, time.January, 1), wallet.NewDate(2025, time.January, 2)} {
+		p := nav.Mul(wallet.NewDecimal(int64(9+i), 1))
+		output.Performance = append(output.Performance, wallet.AllocationPerformance{
+			Date:                 d,
+			Units:                b.Units,
+			Asset:                b.Asset,
+			NetAssetValuePerUnit: p,
+			Value:                b.Units.Mul(p).Round(wallet.CurrencyScale, wallet.RoundHalfUp),
+		})
+	}
+	return output, nil
+}
+
+func getClientAccountStatement(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.GetClientAccountStatementInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if _, aerr := s.account(input.AccountID); aerr != nil {
+		return nil, aerr
+	}
+	if aerr := checkDateRange(input.FromDate, input.ToDate); aerr != nil {
+		return nil, aerr
+	}
+	body, aerr := document(input.Format, "Statement "+input.AccountID)
+	if aerr != nil {
+		return nil, aerr
+	}
+	return wallet.GetClientAccountStatementOutput{
+		FromDate: input.FromDate,
+		ToDate:   input.ToDate,
+		Format:   input.Format,
+		Filename: fmt.Sprintf("statement-%s-%s.%s", input.FromDate, input.ToDate, input.Format),
+		Bytes:    body,
+	}, nil
+}
+
+func document(format wallet.DocumentFormat, title string) ([]byte, *apiError) {
+	switch format {
+	case wallet.DocumentFormatPDF:
+		return []byte("%PDF-1.4\n% " + title + "\n%%EOF\n"), nil
+	case wallet.DocumentFormatHTML:
+		return []byte("<html><body><h1>" + title + "</h1></body></html>"), nil
+	case "":
+		return nil, fieldErrorf(wallet.ErrMissingParameter, "format", "format is required")
+	default:
+		return nil, fieldErrorf(wallet.ErrInvalidParameter, "format", "format must be either pdf or html")
+	}
+}
+
+func getClientAccountRequestConfirmation(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.GetClientAccountRequestConfirmationInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if _, aerr := s.account(input.AccountID); aerr != nil {
+		return nil, aerr
+	}
+	r, aerr := s.request(input.AccountID, input.RequestID)
+	if aerr != nil {
+		return nil, aerr
+	}
+	if input.Format == "" {
+		input.Format = wallet.DocumentFormatPDF
+	}
+	body, aerr := document(input.Format, "Confirmation "+r.ID)
+	if aerr != nil {
+		return nil, aerr
+	}
+	return wallet.GetClientAccountRequestConfirmationOutput{
+		Format:   input.Format,
+		Filename: fmt.Sprintf("confirmation-%s.%s", r.ID, input.Format),
+		Bytes:    body,
+	}, nil
+}
+
+func getClientReferral(s *Server, payload json.RawMessage) (any, *apiError) {
+	return s.state.Referral, nil
+}
+
+func getClientAccountRequestPolicy(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.GetClientAccountRequestPolicyInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if _, aerr := s.account(input.AccountID); aerr != nil {
+		return nil, aerr
+	}
+	if _, aerr := s.request(input.AccountID, input.RequestID); aerr != nil {
+		return nil, aerr
+	}
+	policy, ok := s.state.Policies[input.RequestID]
+	if !ok {
+		return nil, errorf(wallet.ErrInvalidRequestPolicy, "request %q has no policy", input.RequestID)
+	}
+	// the output is encoded after the lock is released
+	policy.Groups = slices.Clone(policy.Groups)
+	policy.Participants = slices.Clone(policy.Participants)
+	return policy, nil
+}
+
+func listFundsForSubscription(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.ListFundsForSubscriptionInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if _, aerr := s.account(input.AccountID); aerr != nil {
+		return nil, aerr
+	}
+	output := wallet.ListFundsForSubscriptionOutput{Funds: []wallet.Fund{}}
+	for _, f := range s.state.Funds {
+		if f.Status == wallet.FundStatusActive {
+			output.Funds = append(output.Funds, f)
+		}
+	}
+	return output, nil
+}
+
+func listClientAccountBalance(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.ListClientAccountBalanceInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if _, aerr := s.account(input.AccountID); aerr != nil {
+		return nil, aerr
+	}
+	output := wallet.ListClientAccountBalanceOutput{}
+	for _, b := range s.state.Balances[input.AccountID] {
+		c := *b
+		c.Value = c.Units.Mul(s.price(c.FundID, c.FundClassSequence)).Round(wallet.CurrencyScale, wallet.RoundHalfUp)
+		output.Balance = append(output.Balance, &c)
+	}
+	return output, nil
+}
+
+func containsPtr(values []*string, v string) bool {
+	for _, p := range values {
+		if p != nil && *p == v {
+			return true
+		}
+	}
+	return false
+}
+
+func listClientAccountRequests(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.ListClientAccountRequestsInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if _, aerr := s.account(input.AccountID); aerr != nil {
+		return nil, aerr
+	}
+	var fromDate, toDate wallet.Date
+	if input.FromDate != nil {
+		fromDate = *input.FromDate
+	}
+	if input.ToDate != nil {
+		toDate = *input.ToDate
+	}
+	if aerr := checkDateRange(fromDate, toDate); aerr != nil {
+		return nil, aerr
+	}
+	if (input.Limit != nil && *input.Limit < 0) || (input.Offset != nil && *input.Offset < 0) {
+		return nil, errorf(wallet.ErrInvalidParameter, "limit and offset must not be negative")
+	}
+	requests := []wallet.ClientAccountRequest{}
+	for _, r := range s.state.Requests[input.AccountID] {
+		switch {
+		case input.RequestID != nil && r.ID != *input.RequestID:
+		case input.FundID != nil && r.FundID != *input.FundID:
+		case len(input.FundIDs) > 0 && !containsPtr(input.FundIDs, r.FundID):
+		case len(input.Types) > 0 && !slices.Contains(input.Types, r.Type):
+		case len(input.Statuses) > 0 && !slices.Contains(input.Statuses, r.Status):
+		case input.CompletedOnly && r.Status != wallet.RequestStatusCompleted:
+		case !fromDate.IsZero() && wallet.DateOf(r.CreatedAt.Time).Before(fromDate):
+		case !toDate.IsZero() && wallet.DateOf(r.CreatedAt.Time).After(toDate):
+		default:
+			requests = append(requests, r)
+		}
+	}
+	// newest first
+	sort.SliceStable(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.After(requests[j].CreatedAt.Time)
+	})
+	if input.Offset != nil {
+		requests = requests[min(*input.Offset, len(requests)):]
+	}
+	if input.Limit != nil && *input.Limit < len(requests) {
+		requests = requests[:*input.Limit]
+	}
+	return wallet.ListClientAccountRequestsOutput{Requests: requests}, nil
+}
+
+func listClientBankAccounts(s *Server, payload json.RawMessage) (any, *apiError) {
+	return wallet.ListClientBankAccountsOutput{BankAccounts: s.state.BankAccounts}, nil
+}
+
+func listDisplayCurrencies(s *Server, payload json.RawMessage) (any, *apiError) {
+	return wallet.ListDisplayCurrenciesOutput{
+		DisplayCurrency: s.state.DisplayCurrency,
+		Currencies:      s.state.Currencies,
+	}, nil
+}
+
+func listClientSuitabilityAssessments(s *Server, payload json.RawMessage) (any, *apiError) {
+	return s.state.SuitabilityAssessments, nil
+}
+
+func listInvestConsents(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.ListInvestConsentsInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if _, aerr := s.account(input.AccountID); aerr != nil {
+		return nil, aerr
+	}
+	if _, _, aerr := s.fundClass(input.FundID, input.FundClassSequence); aerr != nil {
+		return nil, aerr
+	}
+	return wallet.ListInvestConsentsOutput{Consents: s.state.Consents}, nil
+}
+
+func listBanks(s *Server, payload json.RawMessage) (any, *apiError) {
+	return wallet.ListBanksOutput{Banks: s.state.Banks}, nil
+}
+
+func listClientPromos(s *Server, payload json.RawMessage) (any, *apiError) {
+	return wallet.ListClientPromosOutput{Promos: s.state.Promos}, nil
+}
+
+func listClientAccountPerformance(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.ListClientAccountPerformanceInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	for _, id := range input.AccountIDs {
+		if _, aerr := s.account(id); aerr != nil {
+			return nil, aerr
+		}
+	}
+	output := wallet.ListClientAccountPerformanceOutput{}
+	for _, p := range s.state.Performance {
+		if len(input.AccountIDs) == 0 || slices.Contains(input.AccountIDs, p.AccountID) {
+			output.Performance = append(output.Performance, p)
+		}
+	}
+	return output, nil
+}
+
+func listPaymentMethods(s *Server, payload json.RawMessage) (any, *apiError) {
+	return s.state.PaymentMethods, nil
+}
+
+var (
+	hundred = wallet.NewDecimalFromInt(100)
+	percent = wallet.NewDecimal(1, 2)
+)
+
+// quote returns the subscription fee of an investment, discounted by voucherCode when it is valid.
+func (s *Server) quote(accountID string, fundID string, sequence int, amount wallet.Decimal, voucherCode string) (wallet.GetVoucherOutput, *apiError) {
+	if _, aerr := s.account(accountID); aerr != nil {
+		return wallet.GetVoucherOutput{}, aerr
+	}
+	_, class, aerr := s.fundClass(fundID, sequence)
+	if aerr != nil {
+		return wallet.GetVoucherOutput{}, aerr
+	}
+	q := wallet.GetVoucherOutput{
+		Code:                             voucherCode,
+		StrokedSubscriptionFeePercentage: class.SubscriptionFee,
+		AppliedSubscriptionFeePercentage: class.SubscriptionFee,
+	}
+	if discount, ok := s.state.Vouchers[voucherCode]; ok {
+		q.Valid = true
+		q.VoucherDiscountPercentage = discount
+		q.AppliedSubscriptionFeePercentage = class.SubscriptionFee.Mul(hundred.Sub(discount)).Mul(percent)
+	}
+	q.FeeAmount = amount.Mul(q.AppliedSubscriptionFeePercentage).Mul(percent).Round(wallet.CurrencyScale, wallet.RoundHalfUp)
+	q.PostFeeAmount = amount.Sub(q.FeeAmount)
+	return q, nil
+}
+
+func getVoucher(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.GetVoucherInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if input.VoucherCode == nil || *input.VoucherCode == "" {
+		return nil, fieldErrorf(wallet.ErrMissingParameter, "voucherCode", "voucherCode is required")
+	}
+	q, aerr := s.quote(input.AccountID, input.FundID, input.FundClassSequence, input.Amount, *input.VoucherCode)
+	if aerr != nil {
+		return nil, aerr
+	}
+	if !q.Valid {
+		return nil, errorf(wallet.ErrMissingResource, "voucher %q does not exist", *input.VoucherCode)
+	}
+	return q, nil
+}
+
+func getPreviewInvest(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.GetPreviewInvestInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	q, aerr := s.quote(input.AccountID, input.FundID, input.FundClassSequence, input.Amount, "")
+	if aerr != nil {
+		return nil, aerr
+	}
+	return wallet.GetPreviewInvestOutput{
+		StrokedSubscriptionFeePercentage: q.StrokedSubscriptionFeePercentage,
+		AppliedSubscriptionFeePercentage: q.AppliedSubscriptionFeePercentage,
+		PostFeeAmount:                    q.PostFeeAmount,
+		FeeAmount:                        q.FeeAmount,
+	}, nil
+}
+
+func getProjectedFundPrice(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.GetProjectedFundPriceInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	_, class, aerr := s.fundClass(input.FundID, input.FundClassSequence)
+	if aerr != nil {
+		return nil, aerr
+	}
+	return wallet.GetProjectedFundPriceOutput{
+		Asset:                class.BaseCurrency,
+		NetAssetValuePerUnit: s.price(input.FundID, input.FundClassSequence),
+	}, nil
+}
+
+//
+// Commands
+//
+
+func createInvestmentRequest(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.CreateInvestmentRequestInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	account, aerr := s.account(input.AccountID)
+	if aerr != nil {
+		return nil, aerr
+	}
+	if !account.CanInvest {
+		return nil, errorf(wallet.ErrActionNotAllowedForAccountType, "account cannot invest")
+	}
+	fund, class, aerr := s.fundClass(input.FundID, input.FundClassSequence)
+	if aerr != nil {
+		return nil, aerr
+	}
+	if fund.IsOutOfService {
+		return nil, errorf(wallet.ErrActionOutsideFundHours, "%s", fund.OutOfServiceMessage)
+	}
+	if input.Amount.Sign() <= 0 {
+		return nil, fieldErrorf(wallet.ErrMissingParameter, "amount", "amount is required")
+	}
+	minimum := class.MinimumInitialInvestment
+	if s.balance(account.ID, fund.ID, class.Sequence) != nil {
+		minimum = class.MinimumAdditionalInvestment
+	}
+	if input.Amount.Cmp(minimum) < 0 {
+		return nil, fieldErrorf(wallet.ErrInvalidParameter, "amount", "amount must be at least %v", minimum)
+	}
+	for _, c := range s.state.Consents {
+		if !input.Consents[c.Name] {
+			return nil, fieldErrorf(wallet.ErrMissingParameter, "consents", "consent %q is required", c.Name)
+		}
+	}
+	if input.VoucherCode != "" {
+		if _, ok := s.state.Vouchers[input.VoucherCode]; !ok {
+			return nil, fieldErrorf(wallet.ErrInvalidParameter, "voucherCode", "voucher %q is not valid", input.VoucherCode)
+		}
+	}
+	q, aerr := s.quote(account.ID, fund.ID, class.Sequence, input.Amount, input.VoucherCode)
+	if aerr != nil {
+		return nil, aerr
+	}
+	r := wallet.ClientAccountRequest{
+		Type:                 wallet.RequestTypeInvestment,
+		FundID:               fund.ID,
+		FundName:             fund.Name,
+		FundShortName:        fund.ShortName,
+		FundClassLabel:       class.Label,
+		Asset:                class.BaseCurrency,
+		Amount:               input.Amount,
+		PostFeeAmount:        q.PostFeeAmount,
+		FeePercentage:        q.AppliedSubscriptionFeePercentage,
+		StrokedFeePercentage: q.StrokedSubscriptionFeePercentage,
+		FeeAmount:            q.FeeAmount,
+	}
+	if input.VoucherCode != "" {
+		r.VoucherCode = &input.VoucherCode
+	}
+	return wallet.CreateInvestmentRequestOutput{RequestID: s.addRequest(account, r)}, nil
+}
+
+// holding validates that the account holds enough of the fund class to take out amount or units.
+func (s *Server) holding(accountID string, fundID string, sequence int, amount wallet.Decimal, units wallet.Decimal) (*wallet.Balance, *apiError) {
+	if amount.Sign() <= 0 && units.Sign() <= 0 {
+		return nil, errorf(wallet.ErrMissingParameter, "either requestedAmount or units is required")
+	}
+	b := s.balance(accountID, fundID, sequence)
+	if b == nil {
+		return nil, errorf(wallet.ErrInsufficientBalance, "account does not hold the fund")
+	}
+	if b.IsOutOfService {
+		return nil, errorf(wallet.ErrActionOutsideFundHours, "%s", b.OutOfServiceMessage)
+	}
+	nav := s.price(fundID, sequence)
+	if units.Cmp(b.Units) > 0 || amount.Cmp(b.Units.Mul(nav)) > 0 {
+		return nil, errorf(wallet.ErrInsufficientBalance, "account balance is insufficient")
+	}
+	if amount.Sign() > 0 && amount.Cmp(b.MinimumRedemptionAmount) < 0 {
+		return nil, fieldErrorf(wallet.ErrInvalidParameter, "requestedAmount", "requestedAmount must be at least %v", b.MinimumRedemptionAmount)
+	}
+	if units.Sign() > 0 && units.Cmp(b.MinimumRedemptionUnits) < 0 {
+		return nil, fieldErrorf(wallet.ErrInvalidParameter, "units", "units must be at least %v", b.MinimumRedemptionUnits)
+	}
+	return b, nil
+}
+
+func createRedemptionRequest(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.CreateRedemptionRequestInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	account, aerr := s.account(input.AccountID)
+	if aerr != nil {
+		return nil, aerr
+	}
+	if !account.CanRedeem {
+		return nil, errorf(wallet.ErrActionNotAllowedForAccountType, "account cannot redeem")
+	}
+	if input.ToBankAccountNumber != "" && !slices.ContainsFunc(s.state.BankAccounts, func(b wallet.BankAccount) bool {
+		return b.AccountNumber == input.ToBankAccountNumber
+	}) {
+		return nil, errorf(wallet.ErrMissingResource, "bank account %q does not exist", input.ToBankAccountNumber)
+	}
+	b, aerr := s.holding(account.ID, input.FundID, input.FundClassSequence, input.RequestedAmount, input.Units)
+	if aerr != nil {
+		return nil, aerr
+	}
+	r := wallet.ClientAccountRequest{
+		Type:           wallet.RequestTypeRedemption,
+		FundID:         b.FundID,
+		FundName:       b.FundName,
+		FundShortName:  b.FundShortName,
+		FundClassLabel: b.FundClassLabel,
+		Asset:          b.Asset,
+		Amount:         input.RequestedAmount,
+		Units:          input.Units,
+		FeePercentage:  b.RedemptionFeePercentage,
+	}
+	return wallet.CreateRedemptionRequestOutput{RequestID: s.addRequest(account, r)}, nil
+}
+
+func createSwitchRequest(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.CreateSwitchRequestInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	account, aerr := s.account(input.AccountID)
+	if aerr != nil {
+		return nil, aerr
+	}
+	if !account.CanSwitch {
+		return nil, errorf(wallet.ErrActionNotAllowedForAccountType, "account cannot switch")
+	}
+	toFund, toClass, aerr := s.fundClass(input.SwitchToFundID, input.SwitchToFundClassSequence)
+	if aerr != nil {
+		return nil, aerr
+	}
+	if toFund.IsOutOfService {
+		return nil, errorf(wallet.ErrActionOutsideFundHours, "%s", toFund.OutOfServiceMessage)
+	}
+	b, aerr := s.holding(account.ID, input.SwitchFromFundID, input.SwitchFromFundClassSequence, input.RequestedAmount, input.Units)
+	if aerr != nil {
+		return nil, aerr
+	}
+	r := wallet.ClientAccountRequest{
+		Type:           wallet.RequestTypeSwitchOut,
+		FundID:         b.FundID,
+		FundName:       b.FundName,
+		FundShortName:  b.FundShortName,
+		FundClassLabel: b.FundClassLabel,
+		Asset:          b.Asset,
+		Amount:         input.RequestedAmount,
+		Units:          input.Units,
+		FeePercentage:  b.SwitchFeePercentage,
+	}
+	id := s.addRequest(account, r)
+	s.addRequest(account, wallet.ClientAccountRequest{
+		Type:           wallet.RequestTypeSwitchIn,
+		FundID:         toFund.ID,
+		FundName:       toFund.Name,
+		FundShortName:  toFund.ShortName,
+		FundClassLabel: toClass.Label,
+		Asset:          toClass.BaseCurrency,
+		Amount:         input.RequestedAmount,
+	})
+	return wallet.CreateSwitchRequestOutput{RequestID: id}, nil
+}
+
+func createRequestCancellation(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.CreateRequestCancellationInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if _, aerr := s.account(input.AccountID); aerr != nil {
+		return nil, aerr
+	}
+	r, aerr := s.request(input.AccountID, input.RequestID)
+	if aerr != nil {
+		return nil, aerr
+	}
+	if r.Status != wallet.RequestStatusPending {
+		return nil, errorf(wallet.ErrRequestCannotBeCancelled, "request is %s", r.Status)
+	}
+	r.Status = wallet.RequestStatusCancelled
+	return wallet.CreateRequestCancellationOutput{}, nil
+}
+
+func createSuitabilityAssessment(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.CreateSuitabilityAssessmentInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if input.SuitabilityAssessment == nil {
+		return nil, fieldErrorf(wallet.ErrMissingParameter, "suitabilityAssessment", "suitabilityAssessment is required")
+	}
+	a := *input.SuitabilityAssessment
+	a.ID = s.newID()
+	a.CreatedAt = now()
+	s.state.SuitabilityAssessments.Assessments = append(s.state.SuitabilityAssessments.Assessments, a)
+	s.state.SuitabilityAssessments.ShouldAskSuitabilityAssessment = false
+	return wallet.CreateSuitabilityAssessmentOutput{SuitabilityAssessmentID: a.ID}, nil
+}
+
+func createClientBankAccount(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.CreateClientBankAccountInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if input.BankAccount == nil || input.BankAccount.AccountNumber == "" {
+		return nil, fieldErrorf(wallet.ErrMissingParameter, "bankAccount.accountNumber", "bankAccount.accountNumber is required")
+	}
+	for _, b := range s.state.BankAccounts {
+		if b.AccountNumber == input.BankAccount.AccountNumber {
+			return nil, errorf(wallet.ErrAlreadyExists, "bank account already exists")
+		}
+	}
+	b := *input.BankAccount
+	b.Status = "pending"
+	b.CreatedAt = now()
+	s.state.BankAccounts = append(s.state.BankAccounts, b)
+	return wallet.CreateClientBankAccountOutput{}, nil
+}
+
+func updateDisplayCurrency(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.UpdateDisplayCurrencyInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	if !slices.ContainsFunc(s.state.Currencies, func(c wallet.DisplayCurrency) bool {
+		return c.ID == input.DisplayCurrency
+	}) {
+		return nil, fieldErrorf(wallet.ErrInvalidParameter, "displayCurrency", "display currency %q is not supported", input.DisplayCurrency)
+	}
+	s.state.DisplayCurrency = input.DisplayCurrency
+	return wallet.UpdateDisplayCurrencyOutput{}, nil
+}
+
+func updateAccountName(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.UpdateAccountNameInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	account, aerr := s.account(input.AccountID)
+	if aerr != nil {
+		return nil, aerr
+	}
+	if strings.TrimSpace(input.AccountName) == "" {
+		return nil, fieldErrorf(wallet.ErrMissingParameter, "accountName", "accountName is required")
+	}
+	if !account.CanUpdateAccountName {
+		return nil, errorf(wallet.ErrInsufficientAccess, "account name cannot be updated")
+	}
+	account.Name = input.AccountName
+	return wallet.UpdateAccountNameOutput{}, nil
+}
+
+func updateClientProfile(s *Server, payload json.RawMessage) (any, *apiError) {
+	var input wallet.UpdateClientProfileInput
+	if aerr := decode(payload, &input); aerr != nil {
+		return nil, aerr
+	}
+	p := &s.state.Profile
+	if !p.CanUpdateProfile {
+		return nil, errorf(wallet.ErrInsufficientAccess, "profile cannot be updated")
+	}
+	set := func(dst **string, v string) {
+		if v != "" {
+			*dst = &v
+		}
+	}
+	set(&p.Ethnicity, input.Ethnicity)
+	set(&p.DomesticRinggitBorrowing, input.DomesticRinggitBorrowing)
+	set(&p.TaxResidency, input.TaxResidency)
+	set(&p.CountryTax, input.CountryTax)
+	set(&p.TaxIdentificationNo, input.TaxIdentificationNo)
+	return wallet.UpdateClientProfileOutput{}, nil
+}
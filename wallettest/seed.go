@@ -0,0 +1,288 @@
+package wallettest
+
+import (
+	wallet "github.com/halogencapital/wallet-go"
+)
+
+// Seeded identifiers used by [DefaultSeed].
+const (
+	SingleAccountID = "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"
+	JointAccountID  = "b1b2c3d4e5f60718293a4b5c6d7e8f9012345678"
+
+	BitcoinFundID  = "f1f2f3f4f5f60718293a4b5c6d7e8f9012345678"
+	EthereumFundID = "e1e2e3e4e5f60718293a4b5c6d7e8f9012345678"
+
+	PendingRequestID   = "c1c2c3c4c5c60718293a4b5c6d7e8f9012345678"
+	CompletedRequestID = "d1d2d3d4d5d60718293a4b5c6d7e8f9012345678"
+
+	BankAccountNumber = "1234567890"
+	VoucherCode       = "HALOGEN10"
+)
+
+// FundClassKey identifies a fund class.
+type FundClassKey struct {
+	FundID   string
+	Sequence int
+}
+
+// Seed is the in-memory state of a [Server]. Commands received by the server mutate it.
+type Seed struct {
+	Profile  wallet.GetClientProfileOutput
+	Referral wallet.GetClientReferralOutput
+	Accounts []wallet.ClientAccount
+	Funds    []wallet.Fund
+
+	// Prices holds the net asset value per unit of each fund class.
+	Prices map[FundClassKey]wallet.Decimal
+
+	// Balances holds the holdings of each account, keyed by account ID.
+	Balances map[string][]*wallet.Balance
+
+	// Requests holds the requests of each account, keyed by account ID.
+	Requests map[string][]wallet.ClientAccountRequest
+
+	// Policies holds the approval policy of each request, keyed by request ID.
+	Policies map[string]wallet.GetClientAccountRequestPolicyOutput
+
+	// Performance holds the daily value of each account.
+	Performance []wallet.ClientAccountPerformance
+
+	BankAccounts           []wallet.BankAccount
+	Banks                  []wallet.Bank
+	DisplayCurrency        string
+	Currencies             []wallet.DisplayCurrency
+	SuitabilityAssessments wallet.ListClientSuitabilityAssessmentsOutput
+	Consents               []wallet.Consent
+	Promos                 []wallet.Promo
+	PaymentMethods         wallet.ListPaymentMethodsOutput
+
+	// Vouchers holds the subscription fee discount percentage of each voucher code.
+	Vouchers map[string]wallet.Decimal
+}
+
+func dec(s string) wallet.Decimal {
+	return wallet.MustParseDecimal(s)
+}
+
+func timestamp(s string) wallet.Timestamp {
+	ts, err := wallet.ParseTimestamp(s)
+	if err != nil {
+		panic(err)
+	}
+	return ts
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// DefaultSeed returns a fresh seed with one single account, one joint account, two funds,
+// balances, requests, a bank account and a voucher.
+func DefaultSeed() *Seed {
+	return &Seed{
+		Profile: wallet.GetClientProfileOutput{
+			Name:                 "Ahmad bin Abdullah",
+			Nationality:          stringPtr("MY"),
+			NricNo:               stringPtr("900101-14-5678"),
+			Msisdn:               stringPtr("+60123456789"),
+			Email:                stringPtr("ahmad@example.com"),
+			Type:                 "individual",
+			InvestorCategory:     wallet.InvestorCategorySophisticated250k,
+			IsAccountOwner:       true,
+			CanInvestInUnitTrust: true,
+			CanUpdateProfile:     true,
+			Status:               "active",
+			PermanentAddress: &wallet.Address{
+				Type:     "permanent",
+				Line1:    "1 Jalan Ampang",
+				City:     "Kuala Lumpur",
+				Postcode: "50450",
+				Country:  "MY",
+			},
+		},
+		Referral: wallet.GetClientReferralOutput{
+			ReferralCode:         "AHMAD01",
+			ReferredClientsCount: 2,
+		},
+		Accounts: []wallet.ClientAccount{
+			{
+				ID:                   SingleAccountID,
+				Type:                 wallet.AccountTypeSingle,
+				Name:                 "Main",
+				Experience:           wallet.AccountExperienceFundManagement,
+				ExperienceLabel:      "Fund Management",
+				Asset:                "MYR",
+				CanInvest:            true,
+				CanRedeem:            true,
+				CanSwitch:            true,
+				CanUpdateAccountName: true,
+			},
+			{
+				ID:                   JointAccountID,
+				Type:                 wallet.AccountTypeJoint,
+				Name:                 "Family",
+				Experience:           wallet.AccountExperienceFundManagement,
+				ExperienceLabel:      "Fund Management",
+				Asset:                "MYR",
+				CanInvest:            true,
+				CanRedeem:            true,
+				CanSwitch:            true,
+				CanUpdateAccountName: true,
+			},
+		},
+		Funds: []wallet.Fund{
+			{
+				ID:           BitcoinFundID,
+				Type:         "growth",
+				Name:         "Halogen Shariah Bitcoin Fund",
+				ShortName:    "Bitcoin Fund",
+				BaseCurrency: "MYR",
+				Code:         "HSBTCF",
+				RiskRating:   wallet.RiskRatingHigh,
+				RiskScore:    16,
+				Status:       wallet.FundStatusActive,
+				CreatedAt:    timestamp("2023-01-02T00:00:00Z"),
+				Classes: []wallet.FundClass{
+					{
+						Sequence:                    1,
+						Label:                       "Class A",
+						BaseCurrency:                "MYR",
+						SubscriptionFee:             dec("2"),
+						SwitchingFee:                dec("0.5"),
+						MinimumInitialInvestment:    dec("1000"),
+						MinimumAdditionalInvestment: dec("100"),
+						MinimumRedemptionAmount:     dec("100"),
+						LaunchPrice:                 dec("1"),
+					},
+				},
+			},
+			{
+				ID:           EthereumFundID,
+				Type:         "growth",
+				Name:         "Halogen Shariah Ethereum Fund",
+				ShortName:    "Ethereum Fund",
+				BaseCurrency: "MYR",
+				Code:         "HSETHF",
+				RiskRating:   wallet.RiskRatingHigh,
+				RiskScore:    16,
+				Status:       wallet.FundStatusActive,
+				CreatedAt:    timestamp("2023-06-01T00:00:00Z"),
+				Classes: []wallet.FundClass{
+					{
+						Sequence:                    1,
+						Label:                       "Class A",
+						BaseCurrency:                "MYR",
+						SubscriptionFee:             dec("2"),
+						SwitchingFee:                dec("0.5"),
+						MinimumInitialInvestment:    dec("1000"),
+						MinimumAdditionalInvestment: dec("100"),
+						MinimumRedemptionAmount:     dec("100"),
+						LaunchPrice:                 dec("1"),
+					},
+				},
+			},
+		},
+		Prices: map[FundClassKey]wallet.Decimal{
+			{FundID: BitcoinFundID, Sequence: 1}:  dec("1.25"),
+			{FundID: EthereumFundID, Sequence: 1}: dec("0.8"),
+		},
+		Balances: map[string][]*wallet.Balance{
+			SingleAccountID: {
+				{
+					FundID:                  BitcoinFundID,
+					FundClassSequence:       1,
+					FundName:                "Halogen Shariah Bitcoin Fund",
+					FundShortName:           "Bitcoin Fund",
+					FundClassLabel:          "Class A",
+					FundCode:                "HSBTCF",
+					Units:                   dec("8000"),
+					Asset:                   "MYR",
+					Value:                   dec("10000"),
+					ValuedAt:                timestamp("2025-01-02T00:00:00Z"),
+					MinimumRedemptionAmount: dec("100"),
+					MinimumRedemptionUnits:  dec("80"),
+					SwitchFeePercentage:     dec("0.5"),
+					AvailableModes:          []string{"amount", "units"},
+				},
+			},
+			JointAccountID: {},
+		},
+		Requests: map[string][]wallet.ClientAccountRequest{
+			SingleAccountID: {
+				{
+					ID:             CompletedRequestID,
+					Type:           wallet.RequestTypeInvestment,
+					FundID:         BitcoinFundID,
+					FundName:       "Halogen Shariah Bitcoin Fund",
+					FundShortName:  "Bitcoin Fund",
+					FundClassLabel: "Class A",
+					Asset:          "MYR",
+					Amount:         dec("10000"),
+					PostFeeAmount:  dec("9800"),
+					Units:          dec("7840"),
+					FeePercentage:  dec("2"),
+					FeeAmount:      dec("200"),
+					Status:         wallet.RequestStatusCompleted,
+					CreatedAt:      timestamp("2025-01-01T02:00:00Z"),
+				},
+				{
+					ID:             PendingRequestID,
+					Type:           wallet.RequestTypeInvestment,
+					FundID:         BitcoinFundID,
+					FundName:       "Halogen Shariah Bitcoin Fund",
+					FundShortName:  "Bitcoin Fund",
+					FundClassLabel: "Class A",
+					Asset:          "MYR",
+					Amount:         dec("1000"),
+					PostFeeAmount:  dec("980"),
+					FeePercentage:  dec("2"),
+					FeeAmount:      dec("20"),
+					Status:         wallet.RequestStatusPending,
+					CreatedAt:      timestamp("2025-01-03T02:00:00Z"),
+				},
+			},
+			JointAccountID: {},
+		},
+		Policies: map[string]wallet.GetClientAccountRequestPolicyOutput{},
+		Performance: []wallet.ClientAccountPerformance{
+			{Date: wallet.MustParseDate("2025-01-01"), AccountID: SingleAccountID, Value: dec("9800")},
+			{Date: wallet.MustParseDate("2025-01-02"), AccountID: SingleAccountID, Value: dec("10000")},
+		},
+		BankAccounts: []wallet.BankAccount{
+			{
+				AccountNumber:   BankAccountNumber,
+				AccountName:     "Ahmad bin Abdullah",
+				AccountCurrency: "MYR",
+				AccountType:     "savings",
+				BankName:        "Maybank",
+				BankBic:         "MBBEMYKL",
+				Status:          "active",
+				CreatedAt:       timestamp("2023-01-02T00:00:00Z"),
+			},
+		},
+		Banks: []wallet.Bank{
+			{Name: "Maybank", Bic: "MBBEMYKL", Rank: 1},
+			{Name: "CIMB Bank", Bic: "CIBBMYKL", Rank: 2},
+		},
+		DisplayCurrency: "MYR",
+		Currencies: []wallet.DisplayCurrency{
+			{ID: "MYR", Label: "Malaysian Ringgit"},
+			{ID: "USD", Label: "US Dollar"},
+		},
+		SuitabilityAssessments: wallet.ListClientSuitabilityAssessmentsOutput{
+			Assessments: []wallet.SuitabilityAssessment{},
+		},
+		Consents: []wallet.Consent{
+			{Name: "IM", Label: "I have read and understood the Information Memorandum."},
+			{Name: "highRisk", Label: "I understand this fund carries high risk."},
+		},
+		Promos: []wallet.Promo{},
+		PaymentMethods: wallet.ListPaymentMethodsOutput{
+			Duitnow:      true,
+			BankTransfer: true,
+		},
+		Vouchers: map[string]wallet.Decimal{
+			VoucherCode: dec("50"),
+		},
+	}
+}
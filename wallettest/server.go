@@ -0,0 +1,637 @@
+// Package wallettest provides an in-process fake of the Halogen Wallet API for tests.
+//
+// A [Server] implements the "/query" and "/command" endpoints for every API used by
+// [wallet.Client], backed by seeded in-memory state. Requests are authenticated the same
+// way the real server does it: the bearer JWT must be signed with ES256, ES384, RS256, PS256
+// or EdDSA by a registered key, see [Server.SetAlgorithms], and carry a matching bodyHash, uri,
+// exp and a nonce that was never seen before.
+//
+//	srv := wallettest.NewServer(nil)
+//	defer srv.Close()
+//	client := srv.NewClient(nil)
+//	output, err := client.ListClientAccounts(ctx, &wallet.ListClientAccountsInput{})
+package wallettest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+)
+
+// maxTokenTTL is the longest token lifetime the server accepts.
+const maxTokenTTL = 5 * time.Minute
+
+// Fault describes an error the server returns instead of handling a call.
+//
+// A fault with [Fault.AfterHandling] set lets the call through and replaces its response,
+// which simulates a response lost after the command was executed.
+type Fault struct {
+	// Name specifies the API name the fault applies to, for instance "create_investment_request".
+	//
+	// Optional, if empty, the fault applies to any API.
+	Name string
+
+	// StatusCode specifies the HTTP status code of the response.
+	//
+	// Optional, defaulted to the status code the server uses for Code.
+	StatusCode int
+
+	// Code specifies the error code of the response, for instance [wallet.ErrInsufficientBalance].
+	Code string
+
+	// Message specifies the error message of the response.
+	//
+	// Optional, defaulted to Code.
+	Message string
+
+	// RetryAfter sets the Retry-After header of the response in whole seconds.
+	//
+	// Optional.
+	RetryAfter time.Duration
+
+	// Times specifies how many matching calls fail before the fault is removed.
+	//
+	// Optional, defaulted to 1.
+	Times int
+
+	// AfterHandling reports whether the call is handled, and its state changes kept, before
+	// the fault replaces the response.
+	AfterHandling bool
+}
+
+// Call records a request received by the server.
+type Call struct {
+	// Kind is either "query" or "command".
+	Kind string
+	// Name is the API name of the call.
+	Name string
+	// KeyID is the key identifier the call was signed with.
+	KeyID string
+	// Header holds the request headers.
+	Header http.Header
+	// Payload holds the raw JSON payload of the call.
+	Payload json.RawMessage
+	// IdempotencyKey is the idempotency key of a command.
+	IdempotencyKey string
+	// StatusCode is the HTTP status code the server answered with.
+	StatusCode int
+	// RequestID is the identifier the server answered with in the X-Request-Id header.
+	RequestID string
+}
+
+type apiKey struct {
+	publicKey crypto.PublicKey
+	expired   bool
+}
+
+// Server is an in-process fake Halogen Wallet API server. It is safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the server, suitable for [wallet.Options.BaseURL].
+	URL string
+
+	httpServer *httptest.Server
+
+	mu     sync.Mutex
+	state  *Seed
+	keys   map[string]*apiKey
+	nonces map[string]int64
+	faults []*Fault
+	calls  []Call
+	nextID int
+	// algorithms holds the JWT algorithms accepted by the server, nil for all of them.
+	algorithms map[string]bool
+
+	idempotent map[string]idempotentResult
+}
+
+// NewServer starts a server seeded with seed. The caller should call Close when finished.
+//
+// When seed is nil, [DefaultSeed] is used. The server takes ownership of seed.
+func NewServer(seed *Seed) *Server {
+	if seed == nil {
+		seed = DefaultSeed()
+	}
+	s := &Server{
+		state:      seed,
+		keys:       map[string]*apiKey{},
+		nonces:     map[string]int64{},
+		idempotent: map[string]idempotentResult{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/query", func(w http.ResponseWriter, r *http.Request) { s.serve(w, r, "query") })
+	mux.HandleFunc("/command", func(w http.ResponseWriter, r *http.Request) { s.serve(w, r, "command") })
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, errorf(wallet.ErrInvalidRoute, "route %s is not recognized", r.URL.Path))
+	})
+	s.httpServer = httptest.NewServer(mux)
+	s.URL = s.httpServer.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// RegisterKey registers the public key of keyID. Requests signed by keys that are not
+// registered are rejected with [wallet.ErrInvalidAuthToken].
+func (s *Server) RegisterKey(keyID string, publicKey crypto.PublicKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[keyID] = &apiKey{publicKey: publicKey}
+}
+
+// SetAlgorithms restricts the JWT algorithms accepted by the server to algs, for instance
+// [wallet.AlgorithmES256] and [wallet.AlgorithmPS256]. Tokens signed with other algorithms are
+// rejected with [wallet.ErrInvalidAuthToken]. Every algorithm is accepted until it is called.
+func (s *Server) SetAlgorithms(algs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.algorithms = map[string]bool{}
+	for _, alg := range algs {
+		s.algorithms[alg] = true
+	}
+}
+
+// ExpireKey marks keyID as expired. Requests signed by it are rejected with [wallet.ErrExpiredApiKey].
+func (s *Server) ExpireKey(keyID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if k, ok := s.keys[keyID]; ok {
+		k.expired = true
+	}
+}
+
+// GenerateKey generates and registers an EC P-256 key. It returns a random key ID and the
+// PEM encoded private key, ready to be used with [wallet.Client.SetCredentials].
+func (s *Server) GenerateKey() (keyID string, privateKeyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(fmt.Sprintf("wallettest: GenerateKey: %v", err))
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		panic(fmt.Sprintf("wallettest: GenerateKey: %v", err))
+	}
+	keyID = randomHex(20)
+	s.RegisterKey(keyID, key.Public())
+	return keyID, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+// NewClient returns a client pointed at the server and authenticated with a freshly generated key.
+//
+// opts may be nil and is not modified. BaseURL is always overridden, and credentials are set unless
+// CredentialsLoaderFunc or Signer is set.
+func (s *Server) NewClient(opts *wallet.Options) *wallet.Client {
+	var o wallet.Options
+	if opts != nil {
+		o = *opts
+	}
+	o.Environment = wallet.EnvironmentCustom
+	o.BaseURL = s.URL
+	c := wallet.New(&o)
+	if o.CredentialsLoaderFunc == nil && o.Signer == nil {
+		c.SetCredentials(s.GenerateKey())
+	}
+	return c
+}
+
+// Inject queues f. Faults are matched in the order they were injected.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f.Times <= 0 {
+		f.Times = 1
+	}
+	if f.StatusCode == 0 {
+		f.StatusCode = StatusCode(f.Code)
+	}
+	if f.Message == "" {
+		f.Message = f.Code
+	}
+	s.faults = append(s.faults, &f)
+}
+
+// FailNext makes the next call to the API name fail with the error code.
+func (s *Server) FailNext(name string, code string) {
+	s.Inject(Fault{Name: name, Code: code})
+}
+
+// RateLimit makes the next times calls to the API name fail with HTTP 429 and the given Retry-After.
+func (s *Server) RateLimit(name string, retryAfter time.Duration, times int) {
+	s.Inject(Fault{Name: name, Code: wallet.ErrRateLimitExceeded, RetryAfter: retryAfter, Times: times})
+}
+
+// Calls returns the calls received so far, including rejected ones.
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// State calls fn with the server state while holding the server lock, so tests
+// can inspect or modify it between calls.
+func (s *Server) State(fn func(seed *Seed)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.state)
+}
+
+// SetRequestStatus sets the status of a request, as the back office does when it processes it.
+// It reports whether the request was found.
+func (s *Server) SetRequestStatus(accountID string, requestID string, status wallet.RequestStatus) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.state.Requests[accountID] {
+		if r := &s.state.Requests[accountID][i]; r.ID == requestID {
+			r.Status = status
+			return true
+		}
+	}
+	return false
+}
+
+// SignRequest marks the participant with the given email as having signed the request's policy.
+// It reports whether the participant was found.
+func (s *Server) SignRequest(requestID string, email string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	policy, ok := s.state.Policies[requestID]
+	if !ok {
+		return false
+	}
+	for i := range policy.Participants {
+		if p := &policy.Participants[i]; p.Email == email {
+			p.Signed = true
+			p.SignedAt = wallet.Timestamp{Time: time.Now().UTC().Truncate(time.Second)}
+			return true
+		}
+	}
+	return false
+}
+
+// StatusCode returns the HTTP status code the server answers with for the error code.
+func StatusCode(code string) int {
+	switch code {
+	case wallet.ErrExpiredApiKey, wallet.ErrExpiredAuthToken, wallet.ErrInvalidAuthSignature,
+		wallet.ErrInvalidAuthToken, wallet.ErrInvalidPublicKey:
+		return http.StatusUnauthorized
+	case wallet.ErrInsufficientAccess, wallet.ErrUnauthorizedIPAddress:
+		return http.StatusForbidden
+	case wallet.ErrMissingResource, wallet.ErrInvalidRoute:
+		return http.StatusNotFound
+	case wallet.ErrInvalidMethod:
+		return http.StatusMethodNotAllowed
+	case wallet.ErrAlreadyExists:
+		return http.StatusConflict
+	case wallet.ErrRateLimitExceeded:
+		return http.StatusTooManyRequests
+	case wallet.ErrInternal:
+		return http.StatusInternalServerError
+	case wallet.ErrServiceUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// apiError is the error body written by the server.
+type apiError struct {
+	StatusCode int                  `json:"statusCode"`
+	Code       string               `json:"code"`
+	Message    string               `json:"message"`
+	RequestID  string               `json:"requestId,omitempty"`
+	Details    []wallet.ErrorDetail `json:"details,omitempty"`
+
+	retryAfter time.Duration
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+func errorf(code string, format string, args ...any) *apiError {
+	return &apiError{StatusCode: StatusCode(code), Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// fieldErrorf returns an error about one field of the payload.
+func fieldErrorf(code string, field string, format string, args ...any) *apiError {
+	e := errorf(code, format, args...)
+	e.Details = []wallet.ErrorDetail{{Field: field, Code: code, Message: e.Message}}
+	return e
+}
+
+func writeError(w http.ResponseWriter, e *apiError) {
+	if e.retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(e.retryAfter/time.Second), 10))
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(e.StatusCode)
+	json.NewEncoder(w).Encode(e)
+}
+
+type request struct {
+	Name           string          `json:"name"`
+	Payload        json.RawMessage `json:"payload"`
+	IdempotencyKey string          `json:"idempotencyKey"`
+}
+
+// idempotentResult is the outcome of a command, replayed when its idempotency key is reused.
+type idempotentResult struct {
+	name    string
+	payload string
+	output  any
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, kind string) {
+	call := Call{Kind: kind, Header: r.Header.Clone(), RequestID: randomHex(8)}
+	output, aerr := s.handle(r, kind, &call)
+	w.Header().Set("X-Request-Id", call.RequestID)
+
+	s.mu.Lock()
+	call.StatusCode = http.StatusOK
+	if aerr != nil {
+		call.StatusCode = aerr.StatusCode
+	}
+	s.calls = append(s.calls, call)
+	s.mu.Unlock()
+
+	if aerr != nil {
+		aerr.RequestID = call.RequestID
+		writeError(w, aerr)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(output)
+}
+
+func (s *Server) handle(r *http.Request, kind string, call *Call) (any, *apiError) {
+	if r.Method != http.MethodPost {
+		return nil, errorf(wallet.ErrInvalidMethod, "method %s is not allowed", r.Method)
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errorf(wallet.ErrInvalidBodyFormat, "unable to read body")
+	}
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, errorf(wallet.ErrInvalidBodyFormat, "body must be a JSON object")
+	}
+	call.Name = req.Name
+	call.Payload = req.Payload
+	call.IdempotencyKey = req.IdempotencyKey
+
+	keyID, aerr := s.authenticate(r, body)
+	call.KeyID = keyID
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fault, afterHandling := s.takeFault(req.Name)
+	if fault != nil && !afterHandling {
+		return nil, fault
+	}
+	output, aerr := s.dispatch(kind, req)
+	if fault != nil {
+		return nil, fault
+	}
+	return output, aerr
+}
+
+// dispatch runs the handler of req, replaying the outcome of a command whose idempotency key was seen before.
+func (s *Server) dispatch(kind string, req request) (any, *apiError) {
+	handlers := queryHandlers
+	if kind == "command" {
+		handlers = commandHandlers
+	}
+	h, ok := handlers[req.Name]
+	if !ok {
+		return nil, errorf(wallet.ErrInvalidApiName, "%s %q is not recognized", kind, req.Name)
+	}
+	payload := req.Payload
+	if len(payload) == 0 || bytes.Equal(payload, []byte("null")) {
+		payload = []byte("{}")
+	}
+	if kind != "command" || req.IdempotencyKey == "" {
+		return h(s, payload)
+	}
+	if prev, ok := s.idempotent[req.IdempotencyKey]; ok {
+		if prev.name != req.Name || prev.payload != string(payload) {
+			return nil, errorf(wallet.ErrInvalidParameter, "idempotency key was used for a different command")
+		}
+		return replayed(prev.output), nil
+	}
+	output, aerr := h(s, payload)
+	if aerr == nil {
+		s.idempotent[req.IdempotencyKey] = idempotentResult{name: req.Name, payload: string(payload), output: output}
+	}
+	return output, aerr
+}
+
+// replayed marks the output of a replayed command as an existing request.
+func replayed(output any) any {
+	switch o := output.(type) {
+	case wallet.CreateInvestmentRequestOutput:
+		o.Existing = true
+		return o
+	case wallet.CreateRedemptionRequestOutput:
+		o.Existing = true
+		return o
+	case wallet.CreateSwitchRequestOutput:
+		o.Existing = true
+		return o
+	}
+	return output
+}
+
+func (s *Server) takeFault(name string) (*apiError, bool) {
+	for i, f := range s.faults {
+		if f.Name != "" && f.Name != name {
+			continue
+		}
+		f.Times--
+		if f.Times <= 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+		return &apiError{StatusCode: f.StatusCode, Code: f.Code, Message: f.Message, retryAfter: f.RetryAfter}, f.AfterHandling
+	}
+	return nil, false
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+type tokenPayload struct {
+	BodyHash string `json:"bodyHash"`
+	Exp      int64  `json:"exp"`
+	Iat      int64  `json:"iat"`
+	Nonce    string `json:"nonce"`
+	Sub      string `json:"sub"`
+	Uri      string `json:"uri"`
+	Kid      string `json:"kid"`
+}
+
+// authenticate verifies the bearer token of r against body and returns the key ID it was signed with.
+func (s *Server) authenticate(r *http.Request, body []byte) (string, *apiError) {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		return "", errorf(wallet.ErrMissingHeader, "Authorization header is missing")
+	}
+	jwt, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return "", errorf(wallet.ErrInvalidHeader, "Authorization header must be a Bearer token")
+	}
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return "", errorf(wallet.ErrInvalidAuthToken, "token must have 3 parts")
+	}
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil || header.Typ != "JWT" {
+		return "", errorf(wallet.ErrInvalidAuthToken, "token header is invalid")
+	}
+	s.mu.Lock()
+	accepted := s.algorithms == nil || s.algorithms[header.Alg]
+	s.mu.Unlock()
+	if !accepted {
+		return "", errorf(wallet.ErrInvalidAuthToken, "algorithm %q is not supported", header.Alg)
+	}
+	var payload tokenPayload
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return "", errorf(wallet.ErrInvalidAuthToken, "token payload is invalid")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errorf(wallet.ErrInvalidAuthToken, "token signature is not base64url encoded")
+	}
+
+	s.mu.Lock()
+	key, ok := s.keys[payload.Kid]
+	s.mu.Unlock()
+	if !ok {
+		return payload.Kid, errorf(wallet.ErrInvalidAuthToken, "key %q is not registered", payload.Kid)
+	}
+	if key.expired {
+		return payload.Kid, errorf(wallet.ErrExpiredApiKey, "key %q has expired", payload.Kid)
+	}
+	if err := verifySignature(header.Alg, key.publicKey, parts[0]+"."+parts[1], signature); err != nil {
+		return payload.Kid, errorf(wallet.ErrInvalidAuthSignature, "%v", err)
+	}
+
+	now := time.Now().Unix()
+	if payload.Sub != "wallet" {
+		return payload.Kid, errorf(wallet.ErrInvalidAuthToken, "sub claim must be \"wallet\"")
+	}
+	if payload.Exp <= now {
+		return payload.Kid, errorf(wallet.ErrExpiredAuthToken, "token has expired")
+	}
+	if payload.Exp-payload.Iat > int64(maxTokenTTL/time.Second) || payload.Iat > now+int64(maxTokenTTL/time.Second) {
+		return payload.Kid, errorf(wallet.ErrInvalidAuthToken, "token lifetime is too long")
+	}
+	if payload.Uri != r.URL.Path {
+		return payload.Kid, errorf(wallet.ErrInvalidAuthToken, "uri claim %q does not match %q", payload.Uri, r.URL.Path)
+	}
+	bodyHash := sha256.Sum256(body)
+	if payload.BodyHash != fmt.Sprintf("%x", bodyHash) {
+		return payload.Kid, errorf(wallet.ErrInvalidAuthToken, "bodyHash claim does not match the body")
+	}
+	if payload.Nonce == "" {
+		return payload.Kid, errorf(wallet.ErrInvalidAuthToken, "nonce claim is missing")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for nonce, exp := range s.nonces {
+		if exp <= now {
+			delete(s.nonces, nonce)
+		}
+	}
+	if _, seen := s.nonces[payload.Nonce]; seen {
+		return payload.Kid, errorf(wallet.ErrInvalidAuthToken, "nonce has already been used")
+	}
+	s.nonces[payload.Nonce] = payload.Exp
+	return payload.Kid, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func verifySignature(alg string, publicKey crypto.PublicKey, signingString string, signature []byte) error {
+	switch alg {
+	case wallet.AlgorithmES384:
+		hashed := sha512.Sum384([]byte(signingString))
+		return verifyDigest(alg, publicKey, hashed[:], signature)
+	case wallet.AlgorithmEdDSA:
+		return verifyDigest(alg, publicKey, []byte(signingString), signature)
+	default:
+		hashed := sha256.Sum256([]byte(signingString))
+		return verifyDigest(alg, publicKey, hashed[:], signature)
+	}
+}
+
+func verifyDigest(alg string, publicKey crypto.PublicKey, digest []byte, signature []byte) error {
+	valid := false
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if (key.Curve == elliptic.P256() && alg != wallet.AlgorithmES256) || (key.Curve == elliptic.P384() && alg != wallet.AlgorithmES384) {
+			return fmt.Errorf("alg %q does not match EC %s key", alg, key.Curve.Params().Name)
+		}
+		valid = ecdsa.VerifyASN1(key, digest, signature)
+	case *rsa.PublicKey:
+		switch alg {
+		case wallet.AlgorithmRS256:
+			valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature) == nil
+		case wallet.AlgorithmPS256:
+			valid = rsa.VerifyPSS(key, crypto.SHA256, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		default:
+			return fmt.Errorf("alg %q does not match RSA key", alg)
+		}
+	case ed25519.PublicKey:
+		if alg != wallet.AlgorithmEdDSA {
+			return fmt.Errorf("alg %q does not match Ed25519 key", alg)
+		}
+		valid = ed25519.Verify(key, digest, signature)
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	if !valid {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("wallettest: failed to read random bytes: %v", err))
+	}
+	return fmt.Sprintf("%x", b)
+}
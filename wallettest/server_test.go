@@ -0,0 +1,163 @@
+package wallettest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+func TestServerQueries(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	ctx := context.Background()
+
+	accounts, err := c.ListClientAccounts(ctx, &wallet.ListClientAccountsInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts.Accounts) != 2 || !accounts.Amount.Equal(wallet.NewDecimalFromInt(10000)) {
+		t.Fatalf("got %d accounts worth %v, want 2 accounts worth 10000", len(accounts.Accounts), accounts.Amount)
+	}
+	balance, err := c.ListClientAccountBalance(ctx, &wallet.ListClientAccountBalanceInput{AccountID: wallettest.SingleAccountID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balance.Balance) != 1 || !balance.Balance[0].Units.Equal(wallet.NewDecimalFromInt(8000)) {
+		t.Fatalf("unexpected balance %+v", balance.Balance)
+	}
+	limit := 1
+	requests, err := c.ListClientAccountRequests(ctx, &wallet.ListClientAccountRequestsInput{
+		AccountID: wallettest.SingleAccountID,
+		Limit:     &limit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests.Requests) != 1 || requests.Requests[0].ID != wallettest.PendingRequestID {
+		t.Fatalf("unexpected requests %+v", requests.Requests)
+	}
+	_, err = c.ListClientAccounts(ctx, &wallet.ListClientAccountsInput{AccountIDs: []string{"invalid_account_id"}})
+	if werr, ok := err.(wallet.Error); !ok || werr.Code != wallet.ErrInsufficientAccess {
+		t.Fatalf("got %v, want %s", err, wallet.ErrInsufficientAccess)
+	}
+}
+
+func TestServerCommands(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	ctx := context.Background()
+
+	invest, err := c.CreateInvestmentRequest(ctx, &wallet.CreateInvestmentRequestInput{
+		AccountID:         wallettest.JointAccountID,
+		FundID:            wallettest.EthereumFundID,
+		FundClassSequence: 1,
+		Amount:            wallet.NewDecimalFromInt(5000),
+		Consents:          map[string]bool{"IM": true, "highRisk": true},
+		VoucherCode:       wallettest.VoucherCode,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := c.GetClientAccountRequestPolicy(ctx, &wallet.GetClientAccountRequestPolicyInput{
+		AccountID: wallettest.JointAccountID,
+		RequestID: invest.RequestID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policy.Participants) != 2 {
+		t.Fatalf("got %d participants, want 2", len(policy.Participants))
+	}
+	_, err = c.CreateRedemptionRequest(ctx, &wallet.CreateRedemptionRequestInput{
+		AccountID:         wallettest.SingleAccountID,
+		FundID:            wallettest.BitcoinFundID,
+		FundClassSequence: 1,
+		Units:             wallet.NewDecimalFromInt(9000),
+	})
+	if werr, ok := err.(wallet.Error); !ok || werr.Code != wallet.ErrInsufficientBalance {
+		t.Fatalf("got %v, want %s", err, wallet.ErrInsufficientBalance)
+	}
+	if _, err := c.CreateRequestCancellation(ctx, &wallet.CreateRequestCancellationInput{
+		AccountID: wallettest.SingleAccountID,
+		RequestID: wallettest.PendingRequestID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.CreateRequestCancellation(ctx, &wallet.CreateRequestCancellationInput{
+		AccountID: wallettest.SingleAccountID,
+		RequestID: wallettest.PendingRequestID,
+	})
+	if werr, ok := err.(wallet.Error); !ok || werr.Code != wallet.ErrRequestCannotBeCancelled {
+		t.Fatalf("got %v, want %s", err, wallet.ErrRequestCannotBeCancelled)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	ctx := context.Background()
+
+	srv.FailNext("list_banks", wallet.ErrServiceUnavailable)
+	srv.Inject(wallettest.Fault{Name: "create_redemption_request", Code: wallet.ErrInsufficientBalance})
+	_, err := c.CreateRedemptionRequest(ctx, &wallet.CreateRedemptionRequestInput{
+		AccountID:         wallettest.SingleAccountID,
+		FundID:            wallettest.BitcoinFundID,
+		FundClassSequence: 1,
+		Units:             wallet.NewDecimalFromInt(1),
+	})
+	if werr, ok := err.(wallet.Error); !ok || werr.Code != wallet.ErrInsufficientBalance {
+		t.Fatalf("got %v, want %s", err, wallet.ErrInsufficientBalance)
+	}
+	srv.RateLimit("list_payment_methods", time.Second, 1)
+	start := time.Now()
+	if _, err := c.ListPaymentMethods(ctx, &wallet.ListPaymentMethodsInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) < time.Second {
+		t.Fatalf("expected the client to wait for Retry-After")
+	}
+	// the first list_banks call fails with 503 and is retried by the client.
+	if _, err := c.ListBanks(ctx, &wallet.ListBanksInput{}); err != nil {
+		t.Fatal(err)
+	}
+	statuses := []int{}
+	for _, call := range srv.Calls() {
+		statuses = append(statuses, call.StatusCode)
+	}
+	want := []int{http.StatusBadRequest, http.StatusTooManyRequests, http.StatusOK, http.StatusServiceUnavailable, http.StatusOK}
+	if len(statuses) != len(want) {
+		t.Fatalf("got statuses %v, want %v", statuses, want)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Fatalf("got statuses %v, want %v", statuses, want)
+		}
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	ctx := context.Background()
+
+	c := wallet.New(&wallet.Options{BaseURL: srv.URL})
+	_, otherKey := srv.GenerateKey()
+	keyID, _ := srv.GenerateKey()
+	c.SetCredentials(keyID, otherKey)
+	_, err := c.ListBanks(ctx, &wallet.ListBanksInput{})
+	if werr, ok := err.(wallet.Error); !ok || werr.Code != wallet.ErrInvalidAuthSignature {
+		t.Fatalf("got %v, want %s", err, wallet.ErrInvalidAuthSignature)
+	}
+	srv.ExpireKey(keyID)
+	_, err = c.ListBanks(ctx, &wallet.ListBanksInput{})
+	if werr, ok := err.(wallet.Error); !ok || werr.Code != wallet.ErrExpiredApiKey {
+		t.Fatalf("got %v, want %s", err, wallet.ErrExpiredApiKey)
+	}
+}
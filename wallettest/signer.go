@@ -0,0 +1,99 @@
+package wallettest
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"sync"
+
+	wallet "github.com/halogencapital/wallet-go"
+)
+
+// Signer is a [wallet.Signer] test double holding an in-memory key. Every signature it
+// produces is verified locally against its public key before being returned.
+type Signer struct {
+	// KeyID is the random key identifier the signer signs under.
+	KeyID string
+
+	alg string
+	key crypto.Signer
+
+	mu     sync.Mutex
+	signed int
+}
+
+// NewSigner returns a signer with a freshly generated key for alg, one of [wallet.AlgorithmES256],
+// [wallet.AlgorithmES384], [wallet.AlgorithmRS256], [wallet.AlgorithmPS256] or
+// [wallet.AlgorithmEdDSA]. Register it with [Server.RegisterSigner] for the server to accept its tokens.
+func NewSigner(alg string) *Signer {
+	var key crypto.Signer
+	var err error
+	switch alg {
+	case wallet.AlgorithmES256:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case wallet.AlgorithmES384:
+		key, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case wallet.AlgorithmRS256, wallet.AlgorithmPS256:
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case wallet.AlgorithmEdDSA:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		panic(fmt.Sprintf("wallettest: NewSigner: unsupported algorithm %q", alg))
+	}
+	if err != nil {
+		panic(fmt.Sprintf("wallettest: NewSigner: %v", err))
+	}
+	return &Signer{KeyID: randomHex(20), alg: alg, key: key}
+}
+
+// RegisterSigner registers the public key of signer.
+func (s *Server) RegisterSigner(signer *Signer) {
+	s.RegisterKey(signer.KeyID, signer.Public())
+}
+
+// Public returns the public key of the signer.
+func (s *Signer) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+// Signed returns how many signatures the signer produced.
+func (s *Signer) Signed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.signed
+}
+
+func (s *Signer) SigningKey(ctx context.Context) (string, string, error) {
+	return s.KeyID, s.alg, nil
+}
+
+func (s *Signer) Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	if keyID != s.KeyID {
+		return nil, fmt.Errorf("wallettest: Signer: unknown key %q", keyID)
+	}
+	var opts crypto.SignerOpts = crypto.SHA256
+	switch s.alg {
+	case wallet.AlgorithmES384:
+		opts = crypto.SHA384
+	case wallet.AlgorithmPS256:
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+	case wallet.AlgorithmEdDSA:
+		opts = crypto.Hash(0)
+	}
+	signature, err := s.key.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyDigest(s.alg, s.key.Public(), digest, signature); err != nil {
+		return nil, fmt.Errorf("wallettest: Signer: %v", err)
+	}
+	s.mu.Lock()
+	s.signed++
+	s.mu.Unlock()
+	return signature, nil
+}
@@ -0,0 +1,260 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WaitOptions specifies how [Client.WaitForRequest] and [Client.WatchRequests] poll requests.
+type WaitOptions struct {
+	// Interval specifies the wait between two polls. It grows by Multiplier while nothing
+	// changes, up to MaxInterval, and is reset on every change.
+	//
+	// Optional, defaulted to 2 seconds.
+	Interval time.Duration
+
+	// MaxInterval caps the wait between two polls.
+	//
+	// Optional, defaulted to 30 seconds.
+	MaxInterval time.Duration
+
+	// Multiplier specifies the factor the wait grows by after a poll without changes.
+	//
+	// Optional, defaulted to 1.5.
+	Multiplier float64
+
+	// OnEvent, when set, is called by [Client.WaitForRequest] for every event, for instance
+	// to report the progress of the signatories.
+	//
+	// Optional.
+	OnEvent func(RequestEvent)
+}
+
+// withDefaults returns a copy of o with zero fields set to their defaults.
+func (o *WaitOptions) withDefaults() WaitOptions {
+	var w WaitOptions
+	if o != nil {
+		w = *o
+	}
+	if w.Interval <= 0 {
+		w.Interval = 2 * time.Second
+	}
+	if w.MaxInterval < w.Interval {
+		w.MaxInterval = max(30*time.Second, w.Interval)
+	}
+	if w.Multiplier < 1 {
+		w.Multiplier = 1.5
+	}
+	return w
+}
+
+// RequestEvent reports the state of a watched request, either when it is first polled or when
+// its status or signatories changed.
+type RequestEvent struct {
+	// AccountID specifies the account the request belongs to.
+	AccountID string
+
+	// Request is the latest state of the request.
+	Request ClientAccountRequest
+
+	// PreviousStatus is the status before this event, empty on the first event of the request.
+	PreviousStatus RequestStatus
+
+	// Signatories reports the signatures collected for the request. Nil for requests without an
+	// approval policy, such as the requests of single accounts.
+	Signatories *SignatoryProgress
+
+	// Err is set when polling the request failed, in which case the request is no longer watched.
+	Err error
+}
+
+// Done reports whether the request is no longer watched, either because it reached a terminal
+// status or because polling it failed.
+func (e RequestEvent) Done() bool {
+	return e.Err != nil || e.Request.Status.IsTerminal()
+}
+
+// SignatoryProgress reports the signatures collected for a request of a joint account, from
+// [Client.GetClientAccountRequestPolicy].
+type SignatoryProgress struct {
+	// Signed is the number of participants who signed.
+	Signed int
+
+	// Required is the number of signatures the policy requires, the sum of the minimum of its groups.
+	Required int
+
+	// Participants lists the participants of the policy.
+	Participants []PolicyParticipant
+}
+
+// Complete reports whether the required signatures were collected.
+func (p *SignatoryProgress) Complete() bool {
+	return p.Signed >= p.Required
+}
+
+// Pending returns the participants who have not signed yet.
+func (p *SignatoryProgress) Pending() []PolicyParticipant {
+	var pending []PolicyParticipant
+	for _, participant := range p.Participants {
+		if !participant.Signed {
+			pending = append(pending, participant)
+		}
+	}
+	return pending
+}
+
+func newSignatoryProgress(policy *GetClientAccountRequestPolicyOutput) *SignatoryProgress {
+	p := &SignatoryProgress{Participants: policy.Participants}
+	for _, group := range policy.Groups {
+		p.Required += group.Min
+	}
+	for _, participant := range policy.Participants {
+		if participant.Signed {
+			p.Signed++
+		}
+	}
+	return p
+}
+
+// WaitForRequest polls the request until it reaches a terminal status, completed, cancelled or
+// rejected, and returns its final state:
+//
+//	output, err := client.CreateInvestmentRequest(ctx, input)
+//	if err != nil {
+//		return err
+//	}
+//	request, err := client.WaitForRequest(ctx, input.AccountID, output.RequestID, nil)
+//	if err != nil {
+//		return err
+//	}
+//	if request.Status != wallet.RequestStatusCompleted {
+//		// the request was cancelled or rejected
+//	}
+//
+// A request that is not found fails with [ErrMissingResource]. Set [WaitOptions.OnEvent] to
+// follow status transitions and signatures. WaitForRequest returns the context's error when
+// ctx is done first, so use [context.WithTimeout] to bound the wait.
+func (c *Client) WaitForRequest(ctx context.Context, accountID string, requestID string, opts *WaitOptions) (*ClientAccountRequest, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for event := range c.WatchRequests(ctx, accountID, []string{requestID}, opts) {
+		if opts != nil && opts.OnEvent != nil {
+			opts.OnEvent(event)
+		}
+		if event.Err != nil {
+			return nil, event.Err
+		}
+		if event.Done() {
+			return &event.Request, nil
+		}
+	}
+	return nil, ctx.Err()
+}
+
+// WatchRequests polls the requests of an account and sends an event on the returned channel
+// when each of them is first polled, then whenever its status changes or a signatory signs.
+//
+// A request is no longer watched once it reaches a terminal status or polling it fails, see
+// [RequestEvent.Done]. The channel is closed when no request is left to watch, or when ctx is
+// done. Cancel ctx to stop watching early: events are not dropped, so polling pauses until
+// the channel is read or ctx is done.
+func (c *Client) WatchRequests(ctx context.Context, accountID string, requestIDs []string, opts *WaitOptions) <-chan RequestEvent {
+	o := opts.withDefaults()
+	events := make(chan RequestEvent)
+	go func() {
+		defer close(events)
+		watched := make([]*watchedRequest, 0, len(requestIDs))
+		for _, id := range requestIDs {
+			watched = append(watched, &watchedRequest{id: id, hasPolicy: true})
+		}
+		wait := o.Interval
+		for {
+			changed := false
+			remaining := watched[:0]
+			for _, w := range watched {
+				event, ok := c.pollRequest(ctx, accountID, w)
+				if ctx.Err() != nil {
+					return
+				}
+				if ok {
+					changed = true
+					select {
+					case events <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if !event.Done() {
+					remaining = append(remaining, w)
+				}
+			}
+			watched = remaining
+			if len(watched) == 0 {
+				return
+			}
+			if changed {
+				wait = o.Interval
+			} else {
+				wait = min(time.Duration(float64(wait)*o.Multiplier), o.MaxInterval)
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// watchedRequest holds the last known state of a request watched by WatchRequests.
+type watchedRequest struct {
+	id        string
+	polled    bool
+	status    RequestStatus
+	signed    int
+	hasPolicy bool
+}
+
+// pollRequest fetches the request and its policy, if it has one. It reports
+// whether the request was polled for the first time or changed since the last poll.
+func (c *Client) pollRequest(ctx context.Context, accountID string, w *watchedRequest) (RequestEvent, bool) {
+	event := RequestEvent{AccountID: accountID, PreviousStatus: w.status}
+	output, err := c.ListClientAccountRequests(ctx, &ListClientAccountRequestsInput{AccountID: accountID, RequestID: &w.id})
+	if err == nil && len(output.Requests) == 0 {
+		err = Error{
+			Code:    ErrMissingResource,
+			Message: fmt.Sprintf("request %q was not found in account %q", w.id, accountID),
+			APIName: "list_client_account_requests",
+		}
+	}
+	if err != nil {
+		event.Request.ID = w.id
+		event.Err = err
+		return event, true
+	}
+	event.Request = output.Requests[0]
+
+	changed := !w.polled || event.Request.Status != w.status
+	if w.hasPolicy {
+		policy, err := c.GetClientAccountRequestPolicy(ctx, &GetClientAccountRequestPolicyInput{AccountID: accountID, RequestID: w.id})
+		switch {
+		case errors.Is(err, Error{Code: ErrInvalidRequestPolicy}):
+			// the request has no approval policy, for instance on a single account
+			w.hasPolicy = false
+		case err != nil:
+			event.Err = err
+			return event, true
+		default:
+			event.Signatories = newSignatoryProgress(policy)
+			changed = changed || event.Signatories.Signed != w.signed
+			w.signed = event.Signatories.Signed
+		}
+	}
+	w.polled, w.status = true, event.Request.Status
+	return event, changed
+}
@@ -0,0 +1,110 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	wallet "github.com/halogencapital/wallet-go"
+	"github.com/halogencapital/wallet-go/wallettest"
+)
+
+var fastPolling = &wallet.WaitOptions{Interval: 5 * time.Millisecond, MaxInterval: 20 * time.Millisecond}
+
+func TestWaitForRequest(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		srv.SetRequestStatus(wallettest.SingleAccountID, wallettest.PendingRequestID, wallet.RequestStatusCompleted)
+	}()
+	var events []wallet.RequestEvent
+	opts := *fastPolling
+	opts.OnEvent = func(e wallet.RequestEvent) {
+		events = append(events, e)
+	}
+	r, err := c.WaitForRequest(ctx, wallettest.SingleAccountID, wallettest.PendingRequestID, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != wallet.RequestStatusCompleted {
+		t.Fatalf("got status %q, want completed", r.Status)
+	}
+	if len(events) != 2 || events[0].Request.Status != wallet.RequestStatusPending || events[1].PreviousStatus != wallet.RequestStatusPending {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	if events[0].Signatories != nil {
+		t.Fatal("expected no signatories for a single account")
+	}
+
+	_, err = c.WaitForRequest(ctx, wallettest.SingleAccountID, "unknown", fastPolling)
+	if !errors.Is(err, wallet.Error{Code: wallet.ErrMissingResource}) {
+		t.Fatalf("got %v, want %s", err, wallet.ErrMissingResource)
+	}
+
+	timeout, cancelTimeout := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancelTimeout()
+	srv.SetRequestStatus(wallettest.SingleAccountID, wallettest.PendingRequestID, wallet.RequestStatusPending)
+	if _, err := c.WaitForRequest(timeout, wallettest.SingleAccountID, wallettest.PendingRequestID, fastPolling); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWatchRequestsSignatories(t *testing.T) {
+	srv := wallettest.NewServer(nil)
+	defer srv.Close()
+	c := srv.NewClient(nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	const requestID = "joint-request"
+	srv.State(func(seed *wallettest.Seed) {
+		seed.Requests[wallettest.JointAccountID] = append(seed.Requests[wallettest.JointAccountID], wallet.ClientAccountRequest{
+			ID:     requestID,
+			Type:   wallet.RequestTypeRedemption,
+			Status: wallet.RequestStatusPending,
+		})
+		seed.Policies[requestID] = wallet.GetClientAccountRequestPolicyOutput{
+			Groups: []wallet.PolicyGroup{{Label: "holders", Min: 2, Max: 2}},
+			Participants: []wallet.PolicyParticipant{
+				{Email: "primary@example.com", GroupLabel: "holders", Signed: true},
+				{Email: "secondary@example.com", GroupLabel: "holders"},
+			},
+		}
+	})
+
+	var events []wallet.RequestEvent
+	for e := range c.WatchRequests(ctx, wallettest.JointAccountID, []string{requestID}, fastPolling) {
+		if e.Err != nil {
+			t.Fatal(e.Err)
+		}
+		events = append(events, e)
+		switch len(events) {
+		case 1:
+			srv.SignRequest(requestID, "secondary@example.com")
+		case 2:
+			srv.SetRequestStatus(wallettest.JointAccountID, requestID, wallet.RequestStatusCompleted)
+		}
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	first, signed, completed := events[0], events[1], events[2]
+	if first.Signatories == nil || first.Signatories.Signed != 1 || first.Signatories.Required != 2 || first.Signatories.Complete() {
+		t.Fatalf("unexpected first progress %+v", first.Signatories)
+	}
+	if pending := first.Signatories.Pending(); len(pending) != 1 || pending[0].Email != "secondary@example.com" {
+		t.Fatalf("unexpected pending signatories %+v", pending)
+	}
+	if signed.Request.Status != wallet.RequestStatusPending || !signed.Signatories.Complete() {
+		t.Fatalf("expected a signature event, got %+v", signed)
+	}
+	if !completed.Done() || completed.PreviousStatus != wallet.RequestStatusPending {
+		t.Fatalf("expected a completion event, got %+v", completed)
+	}
+}